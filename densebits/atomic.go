@@ -0,0 +1,143 @@
+package densebits
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// Atomic represents a dense bit set that is safe to use concurrently.
+// It has the same memory layout as [Set]
+// but all word accesses are atomic and updates are lock-free.
+//
+// Operations that touch more than one word, such as OnesCount and Snapshot,
+// are atomic per word but do not observe a consistent view of the whole set
+// while other goroutines are modifying it.
+type Atomic []uint64
+
+// NewAtomic returns a new atomic bit set for bits in range [0, n)
+// where n is rounded up to the nearest multiple of 64.
+func NewAtomic(n int) Atomic {
+	return make(Atomic, (n+63)/64)
+}
+
+// Len returns the number of bits in a.
+func (a Atomic) Len() int {
+	return len(a) * 64
+}
+
+// Get reports whether the i-th bit is set to one.
+// The complexity is O(1).
+func (a Atomic) Get(i int) bool {
+	w, b := wordbit(i)
+	return atomic.LoadUint64(&a[w])&b != 0
+}
+
+// Set sets or clears the i-th bit.
+// The complexity is O(1).
+func (a Atomic) Set(i int, to bool) {
+	if to {
+		a.TestAndSet(i)
+	} else {
+		a.TestAndClear(i)
+	}
+}
+
+// Flip sets the i-th bit to one if it zero or to zero it if is one.
+// The complexity is O(1).
+func (a Atomic) Flip(i int) {
+	w, b := wordbit(i)
+	for {
+		old := atomic.LoadUint64(&a[w])
+		if atomic.CompareAndSwapUint64(&a[w], old, old^b) {
+			return
+		}
+	}
+}
+
+// TestAndSet sets the i-th bit to one
+// and reports whether it was already set to one.
+// The complexity is O(1).
+func (a Atomic) TestAndSet(i int) bool {
+	w, b := wordbit(i)
+	return a.atomicOr(w, b)&b != 0
+}
+
+// TestAndClear sets the i-th bit to zero
+// and reports whether it was set to one.
+// The complexity is O(1).
+func (a Atomic) TestAndClear(i int) bool {
+	w, b := wordbit(i)
+	return a.atomicAndNot(w, b)&b != 0
+}
+
+// OnesCount reports the number of one bits (population count) in a.
+// The complexity is O(n).
+func (a Atomic) OnesCount() int {
+	count := 0
+	for i := range a {
+		count += bits.OnesCount64(atomic.LoadUint64(&a[i]))
+	}
+	return count
+}
+
+// Or sets a to the result of a OR p.
+// Bits of p beyond a.Len() are ignored.
+// The complexity is O(n).
+func (a Atomic) Or(p Set) {
+	m := min(len(a), len(p))
+	for i := 0; i < m; i++ {
+		if p[i] != 0 {
+			a.atomicOr(i, p[i])
+		}
+	}
+}
+
+// Reset clears all bits in a.
+// The complexity is O(n).
+func (a Atomic) Reset() {
+	for i := range a {
+		atomic.StoreUint64(&a[i], 0)
+	}
+}
+
+// Snapshot copies the bits of a to s,
+// which will be resized to |a| bits if needed.
+// The complexity is O(n).
+func (a Atomic) Snapshot(s *Set) {
+	s.sizeto(len(a))
+	for i := range a {
+		(*s)[i] = atomic.LoadUint64(&a[i])
+	}
+}
+
+// Store copies the bits of p to a.
+// Bits of p beyond a.Len() are ignored
+// and bits of a beyond p.Len() are cleared.
+// The complexity is O(n).
+func (a Atomic) Store(p Set) {
+	m := min(len(a), len(p))
+	for i := 0; i < m; i++ {
+		atomic.StoreUint64(&a[i], p[i])
+	}
+	for i := m; i < len(a); i++ {
+		atomic.StoreUint64(&a[i], 0)
+	}
+}
+
+func (a Atomic) atomicOr(w int, b uint64) (old uint64) {
+	for {
+		old = atomic.LoadUint64(&a[w])
+		if old&b == b || atomic.CompareAndSwapUint64(&a[w], old, old|b) {
+			return old
+		}
+	}
+}
+
+func (a Atomic) atomicAndNot(w int, b uint64) (old uint64) {
+	for {
+		old = atomic.LoadUint64(&a[w])
+		if old&b == 0 || atomic.CompareAndSwapUint64(&a[w], old, old&^b) {
+			return old
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package densebits
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestAtomicGetSet(t *testing.T) {
+	a := NewAtomic(256)
+	require.Equal(t, 256, a.Len())
+
+	require.True(t, !a.TestAndSet(3))
+	require.True(t, a.TestAndSet(3))
+	require.True(t, a.Get(3))
+	require.True(t, a.TestAndClear(3))
+	require.True(t, !a.TestAndClear(3))
+	require.True(t, !a.Get(3))
+
+	a.Set(100, true)
+	a.Flip(101)
+	require.Equal(t, 2, a.OnesCount())
+	a.Set(100, false)
+	a.Flip(101)
+	require.Equal(t, 0, a.OnesCount())
+}
+
+func TestAtomicSnapshotStore(t *testing.T) {
+	a := NewAtomic(256)
+	p := New(128)
+	p.SetRange(10, 100)
+
+	a.Or(p)
+	require.Equal(t, 90, a.OnesCount())
+
+	var s Set
+	a.Snapshot(&s)
+	require.Equal(t, a.Len(), s.Len())
+	require.Equal(t, 90, s.OnesCount())
+	require.True(t, p.IsSubsetOf(s) && s.IsSubsetOf(p))
+
+	a.Set(200, true)
+	a.Store(p)
+	require.Equal(t, 90, a.OnesCount())
+	require.True(t, !a.Get(200))
+
+	a.Reset()
+	require.Equal(t, 0, a.OnesCount())
+}
+
+func TestAtomicConcurrent(t *testing.T) {
+	a := NewAtomic(1 << 16)
+	var claimed atomic.Int64
+	var wg sync.WaitGroup
+
+	n := runtime.GOMAXPROCS(0)
+	for g := 0; g < n; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < a.Len(); i++ {
+				if !a.TestAndSet(i) {
+					claimed.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(a.Len()), claimed.Load())
+	require.Equal(t, a.Len(), a.OnesCount())
+}
@@ -0,0 +1,221 @@
+package densebits
+
+import "errors"
+
+// ErrLengthMismatch is returned by operations in [Strict] mode
+// when the operands have different lengths.
+var ErrLengthMismatch = errors.New("densebits: length mismatch")
+
+// Mode selects how binary operations handle operands of different lengths.
+type Mode int
+
+const (
+	// Truncate resizes the result to the length of the shorter operand.
+	// This is the mode used by [Set.And], [Set.Or], [Set.AndNot] and [Set.Xor].
+	Truncate Mode = iota
+	// ZeroExtend resizes the result to the length of the longer operand
+	// by treating the shorter operand as if it were extended with zero bits.
+	ZeroExtend
+	// Strict returns [ErrLengthMismatch] if the operands have different lengths.
+	Strict
+)
+
+// Op is a bitwise binary operation.
+type Op int
+
+const (
+	OpAnd    Op = iota // p AND q
+	OpOr               // p OR q
+	OpAndNot           // p AND NOT q
+	OpXor              // p XOR q
+)
+
+func (op Op) apply(x, y uint64) uint64 {
+	switch op {
+	case OpAnd:
+		return x & y
+	case OpOr:
+		return x | y
+	case OpAndNot:
+		return x &^ y
+	case OpXor:
+		return x ^ y
+	}
+	panic("densebits: invalid operation")
+}
+
+// Combine stores the result of p op q in s,
+// which will be resized according to mode.
+// The backing array of s is reused if it has enough capacity,
+// so s may be the same set as p or q to update it in place.
+// Returns [ErrLengthMismatch] in [Strict] mode if |p| != |q|,
+// in which case s is left unmodified.
+// The complexity is O(n).
+func (s *Set) Combine(op Op, p, q Set, mode Mode) error {
+	m := min(len(p), len(q))
+	n := m
+	switch mode {
+	case Truncate:
+	case ZeroExtend:
+		n = max(len(p), len(q))
+	case Strict:
+		if len(p) != len(q) {
+			return ErrLengthMismatch
+		}
+	default:
+		panic("densebits: invalid mode")
+	}
+	// growing s may reallocate it, but p and q
+	// still refer to the original memory which is not modified.
+	s.sizeto(n)
+	for i := 0; i < m; i++ {
+		(*s)[i] = op.apply(p[i], q[i])
+	}
+	for i := m; i < len(p) && i < n; i++ {
+		(*s)[i] = op.apply(p[i], 0)
+	}
+	for i := m; i < len(q) && i < n; i++ {
+		(*s)[i] = op.apply(0, q[i])
+	}
+	return nil
+}
+
+// AndWith stores the result of s AND p in s without reallocating
+// unless s must grow beyond its capacity in [ZeroExtend] mode.
+// See [Set.Combine] for the meaning of mode.
+// The complexity is O(n).
+func (s *Set) AndWith(p Set, mode Mode) error {
+	return s.Combine(OpAnd, *s, p, mode)
+}
+
+// OrWith stores the result of s OR p in s without reallocating
+// unless s must grow beyond its capacity in [ZeroExtend] mode.
+// See [Set.Combine] for the meaning of mode.
+// The complexity is O(n).
+func (s *Set) OrWith(p Set, mode Mode) error {
+	return s.Combine(OpOr, *s, p, mode)
+}
+
+// AndNotWith stores the result of s AND NOT p in s without reallocating
+// unless s must grow beyond its capacity in [ZeroExtend] mode.
+// See [Set.Combine] for the meaning of mode.
+// The complexity is O(n).
+func (s *Set) AndNotWith(p Set, mode Mode) error {
+	return s.Combine(OpAndNot, *s, p, mode)
+}
+
+// XorWith stores the result of s XOR p in s without reallocating
+// unless s must grow beyond its capacity in [ZeroExtend] mode.
+// See [Set.Combine] for the meaning of mode.
+// The complexity is O(n).
+func (s *Set) XorWith(p Set, mode Mode) error {
+	return s.Combine(OpXor, *s, p, mode)
+}
+
+// Trim clears all bits at index n and above,
+// so that s can represent a set of n bits
+// where n is not a multiple of 64.
+// The complexity is O(n).
+func (s Set) Trim(n int) {
+	if n < s.Len() {
+		s.ClearRange(max(n, 0), s.Len())
+	}
+}
+
+// ShiftUp stores the first n bits of p shifted towards higher indexes by k bits in s,
+// so that the i-th bit of p becomes the (i+k)-th bit of s.
+// Bits that are shifted to index n and above are discarded,
+// and s will be resized to n bits rounded up to a multiple of 64 if needed.
+// The bits of s at index n and above are always zero.
+// Panics if n > p.Len().
+// It is safe for s and p to be the same set.
+// The complexity is O(n).
+func (s *Set) ShiftUp(p Set, k, n int) {
+	m := s.shiftsize(p, k, n)
+	ws, bs := k/64, k%64
+	for i := m - 1; i >= 0; i-- {
+		x := wordat(p, i-ws, n) << bs
+		if bs != 0 {
+			x |= wordat(p, i-ws-1, n) >> (64 - bs)
+		}
+		(*s)[i] = x
+	}
+	s.Trim(n)
+}
+
+// ShiftDown stores the first n bits of p shifted towards lower indexes by k bits in s,
+// so that the i-th bit of p becomes the (i-k)-th bit of s.
+// Bits of p at index n and above are treated as zero,
+// and s will be resized to n bits rounded up to a multiple of 64 if needed.
+// The bits of s at index n and above are always zero.
+// Panics if n > p.Len().
+// It is safe for s and p to be the same set.
+// The complexity is O(n).
+func (s *Set) ShiftDown(p Set, k, n int) {
+	m := s.shiftsize(p, k, n)
+	ws, bs := k/64, k%64
+	for i := 0; i < m; i++ {
+		x := wordat(p, i+ws, n) >> bs
+		if bs != 0 {
+			x |= wordat(p, i+ws+1, n) << (64 - bs)
+		}
+		(*s)[i] = x
+	}
+}
+
+// RotateUp stores the first n bits of p rotated towards higher indexes by k bits in s,
+// so that the i-th bit of p becomes the ((i+k) mod n)-th bit of s.
+// The bits of s at index n and above are always zero.
+// Panics if n > p.Len().
+// The complexity is O(n).
+func (s *Set) RotateUp(p Set, k, n int) {
+	if n <= 0 {
+		s.shiftsize(p, 0, n)
+		return
+	}
+	k %= n
+	var t Set
+	t.ShiftDown(p, n-k, n)
+	s.ShiftUp(p, k, n)
+	for i := range t {
+		(*s)[i] |= t[i]
+	}
+}
+
+// RotateDown stores the first n bits of p rotated towards lower indexes by k bits in s,
+// so that the i-th bit of p becomes the ((i-k) mod n)-th bit of s.
+// The bits of s at index n and above are always zero.
+// Panics if n > p.Len().
+// The complexity is O(n).
+func (s *Set) RotateDown(p Set, k, n int) {
+	if n <= 0 {
+		s.shiftsize(p, 0, n)
+		return
+	}
+	s.RotateUp(p, n-k%n, n)
+}
+
+func (s *Set) shiftsize(p Set, k, n int) int {
+	if n < 0 || n > p.Len() {
+		panic("densebits: bit length out of range")
+	}
+	if k < 0 {
+		panic("densebits: negative shift amount")
+	}
+	m := (n + 63) / 64
+	s.sizeto(m)
+	return m
+}
+
+// wordat returns the i-th word of p with the bits at index n and above cleared.
+// Words outside of p are zero.
+func wordat(p Set, i, n int) uint64 {
+	if i < 0 || i*64 >= n {
+		return 0
+	}
+	x := p[i]
+	if r := n - i*64; r < 64 {
+		x &= ^uint64(0) >> (64 - r)
+	}
+	return x
+}
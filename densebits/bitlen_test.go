@@ -0,0 +1,148 @@
+package densebits
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestCombineModes(t *testing.T) {
+	a := Set{0x5555555555555555, 0x5555555555555555}
+	b := Set{0xaaaaaaaaaaaaaaaa}
+
+	var c Set
+	require.NoError(t, c.Combine(OpOr, a, b, Truncate))
+	require.Equal(t, Set{^uint64(0)}, c)
+
+	require.NoError(t, c.Combine(OpOr, a, b, ZeroExtend))
+	require.Equal(t, Set{^uint64(0), 0x5555555555555555}, c)
+
+	require.NoError(t, c.Combine(OpAndNot, b, a, ZeroExtend))
+	require.Equal(t, Set{0xaaaaaaaaaaaaaaaa, 0}, c)
+
+	require.NoError(t, c.Combine(OpXor, b, a, ZeroExtend))
+	require.Equal(t, Set{^uint64(0), 0x5555555555555555}, c)
+
+	require.NoError(t, c.Combine(OpAnd, a, b, ZeroExtend))
+	require.Equal(t, Set{0, 0}, c)
+
+	c = Set{1, 2, 3}
+	err := c.Combine(OpAnd, a, b, Strict)
+	require.True(t, errors.Is(err, ErrLengthMismatch))
+	require.Equal(t, Set{1, 2, 3}, c)
+
+	require.NoError(t, c.Combine(OpAnd, a, a, Strict))
+	require.Equal(t, a, c)
+}
+
+func TestCombineInPlace(t *testing.T) {
+	s := make(Set, 1, 4)
+	s[0] = 0xff
+	p := Set{0xf0, 0xf0, 0xf0}
+	addr := &s[:cap(s)][0]
+
+	require.NoError(t, s.OrWith(p, ZeroExtend))
+	require.Equal(t, Set{0xff, 0xf0, 0xf0}, s)
+	require.True(t, addr == &s[0], "reallocated")
+
+	require.NoError(t, s.AndNotWith(Set{0x0f}, Truncate))
+	require.Equal(t, Set{0xf0}, s)
+
+	require.NoError(t, s.XorWith(Set{0xff}, Strict))
+	require.Equal(t, Set{0x0f}, s)
+
+	require.NoError(t, s.AndWith(Set{0x03, 0xff}, ZeroExtend))
+	require.Equal(t, Set{0x03, 0}, s)
+	require.True(t, addr == &s[0], "reallocated")
+
+	require.True(t, errors.Is(s.AndWith(Set{}, Strict), ErrLengthMismatch))
+}
+
+func TestTrim(t *testing.T) {
+	var s Set
+	s.Not(New(128))
+	s.Trim(100)
+	require.Equal(t, 100, s.OnesCount())
+	s.Trim(200)
+	require.Equal(t, 100, s.OnesCount())
+	s.Trim(0)
+	require.Equal(t, 0, s.OnesCount())
+}
+
+func shiftModel(b []bool, k int, up bool) []bool {
+	d := make([]bool, len(b))
+	for i := range b {
+		j := i + k
+		if !up {
+			j = i - k
+		}
+		if j >= 0 && j < len(b) {
+			d[j] = b[i]
+		}
+	}
+	return d
+}
+
+func rotateModel(b []bool, k int) []bool {
+	d := make([]bool, len(b))
+	for i := range b {
+		d[(i+k)%len(b)] = b[i]
+	}
+	return d
+}
+
+func TestShiftRotateBitLength(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+
+	for _, n := range []int{1, 63, 64, 65, 100, 200} {
+		p := New(n)
+		p.Fill(^uint64(0)) // garbage beyond n must not leak
+		b := make([]bool, n)
+		for i := range b {
+			b[i] = rnd.Intn(2) == 0
+			p.Set(i, b[i])
+		}
+
+		for _, k := range []int{0, 1, 7, 63, 64, 65, n - 1, n, n + 3} {
+			check := func(s Set, expected []bool) {
+				t.Helper()
+				ones := 0
+				for i, x := range expected {
+					require.Equal(t, x, s.Get(i), n, k, i)
+					if x {
+						ones++
+					}
+				}
+				require.Equal(t, ones, s.OnesCount(), n, k)
+			}
+
+			var s Set
+			s.ShiftUp(p, k, n)
+			check(s, shiftModel(b, k, true))
+			s.ShiftDown(p, k, n)
+			check(s, shiftModel(b, k, false))
+			s.RotateUp(p, k, n)
+			check(s, rotateModel(b, k))
+			s.RotateDown(s, k, n)
+			check(s, b)
+
+			q := append(Set(nil), p...)
+			q.ShiftUp(q, k, n)
+			check(q, shiftModel(b, k, true))
+			q = append(q[:0], p...)
+			q.ShiftDown(q, k, n)
+			check(q, shiftModel(b, k, false))
+		}
+	}
+}
+
+func TestShiftEmpty(t *testing.T) {
+	var s Set
+	require.Equal(t, uint64(0), s.ShiftLeft(Set{}, 1))
+	s.RotateLeft(Set{}, 1)
+	s.RotateRight(Set{}, 1)
+	s.RotateUp(Set{}, 1, 0)
+	require.Equal(t, 0, s.Len())
+}
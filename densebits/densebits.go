@@ -74,6 +74,7 @@ func (s Set) Equal(p Set) bool {
 
 // And stores the result of p AND q in s,
 // which will be resized to min(|p|, |q|) bits if needed.
+// Use [Set.Combine] to select a different length mode.
 // The complexity is O(n).
 func (s *Set) And(p, q Set) {
 	m := min(len(p), len(q))
@@ -85,6 +86,7 @@ func (s *Set) And(p, q Set) {
 
 // Or stores the result of p OR q in s,
 // which will be resized to min(|p|, |q|) bits if needed.
+// Use [Set.Combine] to select a different length mode.
 // The complexity is O(n).
 func (s *Set) Or(p, q Set) {
 	m := min(len(p), len(q))
@@ -96,6 +98,7 @@ func (s *Set) Or(p, q Set) {
 
 // AndNot stores the result of p AND NOT q in s,
 // which will be resized to min(|p|, |q|) bits if needed.
+// Use [Set.Combine] to select a different length mode.
 // The complexity is O(n).
 func (s *Set) AndNot(p, q Set) {
 	m := min(len(p), len(q))
@@ -107,6 +110,7 @@ func (s *Set) AndNot(p, q Set) {
 
 // Xor stores the result of p XOR q in s,
 // which will be resized to min(|p|, |q|) bits if needed.
+// Use [Set.Combine] to select a different length mode.
 // The complexity is O(n).
 func (s *Set) Xor(p, q Set) {
 	m := min(len(p), len(q))
@@ -118,6 +122,7 @@ func (s *Set) Xor(p, q Set) {
 
 // Not stores the result of NOT p in s,
 // which will be resized to |p| bits if needed.
+// Use [Set.Trim] afterwards if the bit length is not a multiple of 64.
 // The complexity is O(n).
 func (s *Set) Not(p Set) {
 	s.sizeto(len(p))
@@ -129,10 +134,16 @@ func (s *Set) Not(p Set) {
 // ShiftLeft stores the result of p << n in s,
 // where n is a number from 0 to 64,
 // and s will be resized to |p| bits if needed.
+// The words are treated as a big-endian number,
+// so bits move from higher to lower word indexes.
+// Use [Set.ShiftDown] to shift by bit index over an arbitrary bit length.
 // The complexity is O(n).
 func (s *Set) ShiftLeft(p Set, n int) (remainder uint64) {
-	m := min(len(*s), len(p))
+	m := len(p)
 	s.sizeto(m)
+	if m == 0 {
+		return 0
+	}
 	remainder = p[0] >> uint64(64-n)
 	(*s)[0] = p[0] << n
 	for i := 1; i < m; i++ {
@@ -146,9 +157,12 @@ func (s *Set) ShiftLeft(p Set, n int) (remainder uint64) {
 // ShiftRight stores the result of p >> n in s,
 // where n is a number from 0 to 64,
 // and s will be resized to |p| bits if needed.
+// The words are treated as a big-endian number,
+// so bits move from lower to higher word indexes.
+// Use [Set.ShiftUp] to shift by bit index over an arbitrary bit length.
 // The complexity is O(n).
 func (s *Set) ShiftRight(p Set, n int) (remainder uint64) {
-	m := min(len(*s), len(p))
+	m := len(p)
 	s.sizeto(m)
 	mask := (uint64(1) << n) - 1
 	for i := 0; i < m; i++ {
@@ -166,7 +180,9 @@ func (s *Set) ShiftRight(p Set, n int) (remainder uint64) {
 // The complexity is O(n).
 func (s *Set) RotateLeft(p Set, n int) {
 	remainder := s.ShiftLeft(p, n)
-	(*s)[len(*s)-1] |= remainder
+	if len(*s) != 0 {
+		(*s)[len(*s)-1] |= remainder
+	}
 }
 
 // RotateRight stores the result of p right rotated by n bits in s,
@@ -175,7 +191,9 @@ func (s *Set) RotateLeft(p Set, n int) {
 // The complexity is O(n).
 func (s *Set) RotateRight(p Set, n int) {
 	remainder := s.ShiftRight(p, n)
-	(*s)[0] |= remainder
+	if len(*s) != 0 {
+		(*s)[0] |= remainder
+	}
 }
 
 // Fill sets all words to x.
@@ -213,10 +231,10 @@ func (s Set) String() string {
 	sb.Grow(2 + 16*len(s) + len(s) - 1)
 	sb.WriteByte('[')
 	if len(s) > 0 {
-		sb.Write(strconv.AppendUint(b[:], s[0], 16))
+		sb.Write(strconv.AppendUint(b[:0], s[0], 16))
 		for _, x := range s[1:] {
 			sb.WriteByte(' ')
-			sb.Write(strconv.AppendUint(b[:], x, 16))
+			sb.Write(strconv.AppendUint(b[:0], x, 16))
 		}
 	}
 	sb.WriteByte(']')
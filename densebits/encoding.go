@@ -0,0 +1,118 @@
+package densebits
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidBinary = errors.New("densebits: invalid binary encoding")
+	errInvalidJSON   = errors.New("densebits: invalid JSON encoding: expected a base64 string")
+)
+
+// Parse parses a string in the format produced by [Set.String],
+// which is a list of hexadecimal words enclosed in brackets
+// and separated by spaces, such as "[ff 0 1]".
+func Parse(str string) (Set, error) {
+	body, ok := strings.CutPrefix(str, "[")
+	if ok {
+		body, ok = strings.CutSuffix(body, "]")
+	}
+	if !ok {
+		return nil, fmt.Errorf("densebits: invalid text encoding %q: expected enclosing brackets", str)
+	}
+	if body == "" {
+		return Set{}, nil
+	}
+	words := strings.Split(body, " ")
+	s := make(Set, len(words))
+	for i, word := range words {
+		x, err := strconv.ParseUint(word, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("densebits: invalid text encoding: word %d: %w", i, err)
+		}
+		s[i] = x
+	}
+	return s, nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+// The text format is the same as [Set.String].
+func (s Set) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+// It accepts the format produced by [Set.MarshalText].
+func (s *Set) UnmarshalText(text []byte) error {
+	p, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*s = p
+	return nil
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler].
+// The binary format is the number of bits encoded as a uvarint
+// followed by the words in little endian byte order.
+func (s Set) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, binary.MaxVarintLen64+8*len(s))
+	b = binary.AppendUvarint(b, uint64(s.Len()))
+	for _, x := range s {
+		b = binary.LittleEndian.AppendUint64(b, x)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+// It accepts the format produced by [Set.MarshalBinary].
+func (s *Set) UnmarshalBinary(b []byte) error {
+	n, k := binary.Uvarint(b)
+	if k <= 0 {
+		return fmt.Errorf("%w: malformed bit length", errInvalidBinary)
+	}
+	if n%64 != 0 {
+		return fmt.Errorf("%w: bit length %d is not a multiple of 64", errInvalidBinary, n)
+	}
+	b = b[k:]
+	if uint64(len(b)) != n/8 {
+		return fmt.Errorf("%w: bit length %d does not match data size of %d bytes", errInvalidBinary, n, len(b))
+	}
+	s.sizeto(int(n / 64))
+	for i := range *s {
+		(*s)[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	return nil
+}
+
+// MarshalJSON implements [encoding/json.Marshaler].
+// The JSON format is the binary format encoded as a base64 string.
+func (s Set) MarshalJSON() ([]byte, error) {
+	b, _ := s.MarshalBinary()
+	enc := base64.StdEncoding
+	d := make([]byte, enc.EncodedLen(len(b))+2)
+	d[0] = '"'
+	enc.Encode(d[1:], b)
+	d[len(d)-1] = '"'
+	return d, nil
+}
+
+// UnmarshalJSON implements [encoding/json.Unmarshaler].
+// It accepts the format produced by [Set.MarshalJSON].
+func (s *Set) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errInvalidJSON
+	}
+	enc := base64.StdEncoding
+	b := make([]byte, enc.DecodedLen(len(data)-2))
+	n, err := enc.Decode(b, data[1:len(data)-1])
+	if err != nil {
+		return fmt.Errorf("densebits: invalid JSON encoding: %w", err)
+	}
+	return s.UnmarshalBinary(b[:n])
+}
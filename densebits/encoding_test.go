@@ -0,0 +1,65 @@
+package densebits
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestEncodingText(t *testing.T) {
+	a := Set{0x5555555555555555, 0, 0xaaaaaaaaaaaaaaaa}
+	text, err := a.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, a.String(), string(text))
+
+	var b Set
+	require.NoError(t, b.UnmarshalText(text))
+	require.Equal(t, a, b)
+
+	require.NoError(t, b.UnmarshalText([]byte("[]")))
+	require.Equal(t, 0, b.Len())
+
+	for _, invalid := range []string{"", "[", "ff", "[ff", "[ff  0]", "[xyz]", "[10000000000000000]"} {
+		require.True(t, b.UnmarshalText([]byte(invalid)) != nil, invalid)
+	}
+}
+
+func TestEncodingBinary(t *testing.T) {
+	a := Set{0x5555555555555555, 0, 0xaaaaaaaaaaaaaaaa}
+	data, err := a.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, 2+8*len(a), len(data))
+
+	b := New(1024)
+	require.NoError(t, b.UnmarshalBinary(data))
+	require.Equal(t, a, b)
+
+	data, _ = Set{}.MarshalBinary()
+	require.NoError(t, b.UnmarshalBinary(data))
+	require.Equal(t, 0, b.Len())
+
+	for _, invalid := range [][]byte{nil, {0x80}, {1}, {64}, {64, 1, 2, 3}} {
+		err := b.UnmarshalBinary(invalid)
+		require.True(t, errors.Is(err, errInvalidBinary), invalid)
+	}
+}
+
+func TestEncodingJSON(t *testing.T) {
+	type config struct {
+		Mask Set `json:"mask"`
+	}
+
+	a := config{Set{0x5555555555555555, 0xaaaaaaaaaaaaaaaa}}
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+
+	var b config
+	require.NoError(t, json.Unmarshal(data, &b))
+	require.Equal(t, a, b)
+
+	require.True(t, json.Unmarshal([]byte(`{"mask":42}`), &b) != nil)
+	require.True(t, json.Unmarshal([]byte(`{"mask":"!!"}`), &b) != nil)
+	require.True(t, json.Unmarshal([]byte(`{"mask":"AQ=="}`), &b) != nil)
+}
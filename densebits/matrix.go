@@ -0,0 +1,193 @@
+package densebits
+
+import "math/bits"
+
+// BitMatrix represents a two-dimensional dense bit matrix.
+// The rows are stored contiguously in a single [Set],
+// each row padded to a multiple of 64 bits.
+// Use it to represent relations such as graph adjacency and reachability.
+type BitMatrix struct {
+	dat    Set
+	rows   int
+	cols   int
+	stride int
+}
+
+// NewBitMatrix returns a new zero bit matrix of the given dimensions.
+func NewBitMatrix(rows, cols int) *BitMatrix {
+	var m BitMatrix
+	m.resize(rows, cols)
+	return &m
+}
+
+// Rows reports the number of rows in m.
+func (m *BitMatrix) Rows() int {
+	return m.rows
+}
+
+// Cols reports the number of columns in m.
+func (m *BitMatrix) Cols() int {
+	return m.cols
+}
+
+// Get reports whether the bit at row r and column c is set to one.
+// The complexity is O(1).
+func (m *BitMatrix) Get(r, c int) bool {
+	return m.dat.Get(m.index(r, c))
+}
+
+// Set sets or clears the bit at row r and column c.
+// The complexity is O(1).
+func (m *BitMatrix) Set(r, c int, to bool) {
+	m.dat.Set(m.index(r, c), to)
+}
+
+// Flip flips the bit at row r and column c.
+// The complexity is O(1).
+func (m *BitMatrix) Flip(r, c int) {
+	m.dat.Flip(m.index(r, c))
+}
+
+// Row returns the r-th row of m as a Set that shares memory with m.
+// Its length is Cols() rounded up to the nearest multiple of 64.
+// The bits beyond Cols() must be left zero.
+func (m *BitMatrix) Row(r int) Set {
+	if r < 0 || r >= m.rows {
+		panic("densebits: row index out of range")
+	}
+	lo := r * m.stride
+	hi := lo + m.stride
+	return m.dat[lo:hi:hi]
+}
+
+// OnesCount reports the number of one bits in m.
+// The complexity is O(n).
+func (m *BitMatrix) OnesCount() int {
+	return m.dat.OnesCount()
+}
+
+// Equal reports whether m and p have the same dimensions and bits.
+// The complexity is O(n).
+func (m *BitMatrix) Equal(p *BitMatrix) bool {
+	return m.rows == p.rows && m.cols == p.cols && m.dat.Equal(p.dat)
+}
+
+// Reset sets all bits in m to zero.
+// The complexity is O(n).
+func (m *BitMatrix) Reset() {
+	m.dat.Reset()
+}
+
+// OrRow stores the result of row dst OR row src in row dst.
+// The complexity is O(n).
+func (m *BitMatrix) OrRow(dst, src int) {
+	d, s := m.Row(dst), m.Row(src)
+	for i := range d {
+		d[i] |= s[i]
+	}
+}
+
+// AndRow stores the result of row dst AND row src in row dst.
+// The complexity is O(n).
+func (m *BitMatrix) AndRow(dst, src int) {
+	d, s := m.Row(dst), m.Row(src)
+	for i := range d {
+		d[i] &= s[i]
+	}
+}
+
+// AndNotRow stores the result of row dst AND NOT row src in row dst.
+// The complexity is O(n).
+func (m *BitMatrix) AndNotRow(dst, src int) {
+	d, s := m.Row(dst), m.Row(src)
+	for i := range d {
+		d[i] &^= s[i]
+	}
+}
+
+// XorRow stores the result of row dst XOR row src in row dst.
+// The complexity is O(n).
+func (m *BitMatrix) XorRow(dst, src int) {
+	d, s := m.Row(dst), m.Row(src)
+	for i := range d {
+		d[i] ^= s[i]
+	}
+}
+
+// Transpose stores the transpose of p in m,
+// which will be resized to p.Cols() rows and p.Rows() columns.
+// It is safe for m and p to be the same matrix.
+// The complexity is O(n).
+func (m *BitMatrix) Transpose(p *BitMatrix) {
+	var t BitMatrix
+	t.resize(p.cols, p.rows)
+	for r := 0; r < p.rows; r++ {
+		for w, x := range p.Row(r) {
+			for ; x != 0; x &= x - 1 {
+				c := w*64 + bits.TrailingZeros64(x)
+				t.Set(c, r, true)
+			}
+		}
+	}
+	*m = t
+}
+
+// Mul stores the boolean matrix product of p and q in m,
+// which will be resized to p.Rows() rows and q.Cols() columns.
+// Bit (r, c) of the product is set if there is a k such that
+// both p(r, k) and q(k, c) are set.
+// Panics if p.Cols() != q.Rows().
+// It is safe for m to be the same matrix as p or q.
+// The complexity is O(n^3/64).
+func (m *BitMatrix) Mul(p, q *BitMatrix) {
+	if p.cols != q.rows {
+		panic("densebits: matrix dimension mismatch")
+	}
+	var t BitMatrix
+	t.resize(p.rows, q.cols)
+	for r := 0; r < p.rows; r++ {
+		d := t.Row(r)
+		for w, x := range p.Row(r) {
+			for ; x != 0; x &= x - 1 {
+				k := w*64 + bits.TrailingZeros64(x)
+				for i, y := range q.Row(k) {
+					d[i] |= y
+				}
+			}
+		}
+	}
+	*m = t
+}
+
+// TransitiveClosure replaces m by its transitive closure
+// using Warshall's algorithm, so that bit (r, c) is set
+// if there is a path from r to c.
+// Panics if m is not square.
+// The complexity is O(n^3/64).
+func (m *BitMatrix) TransitiveClosure() {
+	if m.rows != m.cols {
+		panic("densebits: matrix is not square")
+	}
+	for k := 0; k < m.rows; k++ {
+		kw, kb := wordbit(k)
+		for r := 0; r < m.rows; r++ {
+			if m.dat[r*m.stride+kw]&kb != 0 {
+				m.OrRow(r, k)
+			}
+		}
+	}
+}
+
+func (m *BitMatrix) index(r, c int) int {
+	if r < 0 || r >= m.rows || c < 0 || c >= m.cols {
+		panic("densebits: matrix index out of range")
+	}
+	return r*m.stride*64 + c
+}
+
+func (m *BitMatrix) resize(rows, cols int) {
+	m.rows = rows
+	m.cols = cols
+	m.stride = (cols + 63) / 64
+	m.dat = New(rows * m.stride * 64)
+}
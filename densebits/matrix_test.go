@@ -0,0 +1,119 @@
+package densebits
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestMatrixGetSet(t *testing.T) {
+	m := NewBitMatrix(3, 100)
+	require.Equal(t, 3, m.Rows())
+	require.Equal(t, 100, m.Cols())
+
+	m.Set(0, 0, true)
+	m.Set(1, 99, true)
+	m.Flip(2, 64)
+	require.True(t, m.Get(0, 0))
+	require.True(t, m.Get(1, 99))
+	require.True(t, m.Get(2, 64))
+	require.True(t, !m.Get(2, 63))
+	require.Equal(t, 3, m.OnesCount())
+	require.Equal(t, 1, m.Row(1).OnesCount())
+
+	m.Set(0, 0, false)
+	require.Equal(t, 2, m.OnesCount())
+
+	m.Reset()
+	require.Equal(t, 0, m.OnesCount())
+
+	var panicked bool
+	func() {
+		defer func() { panicked = recover() != nil }()
+		m.Get(0, 100)
+	}()
+	require.True(t, panicked)
+}
+
+func TestMatrixRowOps(t *testing.T) {
+	m := NewBitMatrix(2, 128)
+	m.Row(0).SetRange(0, 64)
+	m.Row(1).SetRange(32, 128)
+
+	m.OrRow(0, 1)
+	require.Equal(t, 128, m.Row(0).OnesCount())
+	m.AndRow(0, 1)
+	require.Equal(t, 96, m.Row(0).OnesCount())
+	m.XorRow(0, 1)
+	require.Equal(t, 0, m.Row(0).OnesCount())
+	m.Row(0).SetRange(0, 128)
+	m.AndNotRow(0, 1)
+	require.Equal(t, 32, m.Row(0).OnesCount())
+}
+
+func TestMatrixTranspose(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	m := NewBitMatrix(70, 130)
+	for i := 0; i < 500; i++ {
+		m.Set(rnd.Intn(m.Rows()), rnd.Intn(m.Cols()), true)
+	}
+
+	var tr BitMatrix
+	tr.Transpose(m)
+	require.Equal(t, m.Cols(), tr.Rows())
+	require.Equal(t, m.Rows(), tr.Cols())
+	require.Equal(t, m.OnesCount(), tr.OnesCount())
+	for r := 0; r < m.Rows(); r++ {
+		for c := 0; c < m.Cols(); c++ {
+			require.Equal(t, m.Get(r, c), tr.Get(c, r))
+		}
+	}
+
+	tr.Transpose(&tr)
+	require.True(t, tr.Equal(m))
+}
+
+func TestMatrixMul(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	p := NewBitMatrix(20, 90)
+	q := NewBitMatrix(90, 70)
+	for i := 0; i < 100; i++ {
+		p.Set(rnd.Intn(p.Rows()), rnd.Intn(p.Cols()), true)
+		q.Set(rnd.Intn(q.Rows()), rnd.Intn(q.Cols()), true)
+	}
+
+	var m BitMatrix
+	m.Mul(p, q)
+	require.Equal(t, 20, m.Rows())
+	require.Equal(t, 70, m.Cols())
+
+	for r := 0; r < m.Rows(); r++ {
+		for c := 0; c < m.Cols(); c++ {
+			expected := false
+			for k := 0; k < p.Cols(); k++ {
+				expected = expected || (p.Get(r, k) && q.Get(k, c))
+			}
+			require.Equal(t, expected, m.Get(r, c))
+		}
+	}
+}
+
+func TestMatrixTransitiveClosure(t *testing.T) {
+	// 0 -> 1 -> 2 -> 3, 4 -> 4, 5 isolated
+	m := NewBitMatrix(6, 6)
+	m.Set(0, 1, true)
+	m.Set(1, 2, true)
+	m.Set(2, 3, true)
+	m.Set(4, 4, true)
+	m.TransitiveClosure()
+
+	for _, c := range []int{1, 2, 3} {
+		require.True(t, m.Get(0, c))
+	}
+	require.True(t, m.Get(1, 3))
+	require.True(t, !m.Get(3, 0))
+	require.True(t, m.Get(4, 4))
+	require.Equal(t, 0, m.Row(5).OnesCount())
+	require.Equal(t, 7, m.OnesCount())
+}
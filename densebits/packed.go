@@ -0,0 +1,132 @@
+package densebits
+
+import "math/bits"
+
+// Packed represents an array of unsigned integers of equal bit width
+// that are packed contiguously into the words of a [Set].
+// Use it to store many small integers in a fraction of the memory
+// that a slice of a fixed size integer type would need.
+//
+// The width is automatically increased and all values are re-packed
+// when a value is stored that does not fit in the current width.
+//
+// The zero value for Packed is an empty array of width 1 ready to use.
+type Packed struct {
+	dat   Set
+	len   int
+	width int
+}
+
+// NewPacked returns a new array of n zero values of the given bit width,
+// which must be a number from 1 to 64.
+func NewPacked(n, width int) *Packed {
+	if width < 1 || width > 64 {
+		panic("densebits: packed width out of range")
+	}
+	return &Packed{
+		dat:   New(n * width),
+		len:   n,
+		width: width,
+	}
+}
+
+// Len reports the number of values in p.
+func (p *Packed) Len() int {
+	return p.len
+}
+
+// Width reports the number of bits per value in p.
+func (p *Packed) Width() int {
+	return max(p.width, 1)
+}
+
+// Get returns the i-th value.
+// The complexity is O(1).
+func (p *Packed) Get(i int) uint64 {
+	if i < 0 || i >= p.len {
+		panic("densebits: packed index out of range")
+	}
+	return p.dat.getbits(i*p.width, p.width)
+}
+
+// Set sets the i-th value to x,
+// widening p first if x does not fit in Width() bits.
+// The complexity is O(1), or O(n) if p is widened.
+func (p *Packed) Set(i int, x uint64) {
+	if i < 0 || i >= p.len {
+		panic("densebits: packed index out of range")
+	}
+	p.Widen(bits.Len64(x))
+	p.dat.setbits(i*p.width, p.width, x)
+}
+
+// Append appends x to the end of p,
+// widening p first if x does not fit in Width() bits.
+// The complexity is O(1) amortized, or O(n) if p is widened.
+func (p *Packed) Append(x uint64) {
+	p.Widen(bits.Len64(x))
+	p.dat.Grow((p.len + 1) * p.width)
+	p.dat.setbits(p.len*p.width, p.width, x)
+	p.len++
+}
+
+// Unpack copies the values of p to d
+// and returns the number of values copied,
+// which is the minimum of len(d) and p.Len().
+// The complexity is O(n).
+func (p *Packed) Unpack(d []uint64) int {
+	n := min(len(d), p.len)
+	for i := 0; i < n; i++ {
+		d[i] = p.dat.getbits(i*p.width, p.width)
+	}
+	return n
+}
+
+// Widen ensures that p has a width of at least width bits,
+// re-packing all values in place if the width is increased.
+// The complexity is O(n) if p is widened.
+func (p *Packed) Widen(width int) {
+	if width > 64 {
+		panic("densebits: packed width out of range")
+	}
+	if p.width == 0 {
+		p.width = 1
+	}
+	if width <= p.width {
+		return
+	}
+	p.dat.Grow(p.len * width)
+	// Move the values starting from the last one, so that
+	// every value is read before its bits are overwritten.
+	for i := p.len - 1; i >= 0; i-- {
+		x := p.dat.getbits(i*p.width, p.width)
+		p.dat.setbits(i*width, width, x)
+	}
+	p.width = width
+}
+
+// getbits returns the n bits starting at bit position i,
+// where n is a number from 1 to 64.
+func (s Set) getbits(i, n int) uint64 {
+	w, _ := wordbit(i)
+	off := i % 64
+	mask := ^uint64(0) >> (64 - n)
+	x := s[w] >> off
+	if off+n > 64 {
+		x |= s[w+1] << (64 - off)
+	}
+	return x & mask
+}
+
+// setbits stores the low n bits of x at bit position i,
+// where n is a number from 1 to 64.
+func (s Set) setbits(i, n int, x uint64) {
+	w, _ := wordbit(i)
+	off := i % 64
+	mask := ^uint64(0) >> (64 - n)
+	x &= mask
+	s[w] = s[w]&^(mask<<off) | x<<off
+	if off+n > 64 {
+		s[w+1] = s[w+1]&^(mask>>(64-off)) | x>>(64-off)
+	}
+}
@@ -0,0 +1,72 @@
+package densebits
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestPackedGetSet(t *testing.T) {
+	p := NewPacked(100, 7)
+	require.Equal(t, 100, p.Len())
+	require.Equal(t, 7, p.Width())
+
+	for i := 0; i < p.Len(); i++ {
+		p.Set(i, uint64(i))
+	}
+	for i := 0; i < p.Len(); i++ {
+		require.Equal(t, uint64(i), p.Get(i))
+	}
+
+	p.Set(50, 0)
+	require.Equal(t, uint64(49), p.Get(49))
+	require.Equal(t, uint64(0), p.Get(50))
+	require.Equal(t, uint64(51), p.Get(51))
+}
+
+func TestPackedWiden(t *testing.T) {
+	p := NewPacked(10, 3)
+	for i := 0; i < p.Len(); i++ {
+		p.Set(i, uint64(i%8))
+	}
+
+	p.Set(3, 1000)
+	require.Equal(t, 10, p.Width())
+	require.Equal(t, uint64(1000), p.Get(3))
+	for i := 0; i < p.Len(); i++ {
+		if i != 3 {
+			require.Equal(t, uint64(i%8), p.Get(i))
+		}
+	}
+
+	p.Set(9, math.MaxUint64)
+	require.Equal(t, 64, p.Width())
+	require.Equal(t, uint64(math.MaxUint64), p.Get(9))
+	require.Equal(t, uint64(1000), p.Get(3))
+	require.Equal(t, uint64(8%8), p.Get(8))
+}
+
+func TestPackedAppendUnpack(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	var p Packed
+	require.Equal(t, 0, p.Len())
+	require.Equal(t, 1, p.Width())
+
+	var expected []uint64
+	for i := 0; i < 1000; i++ {
+		x := rnd.Uint64() >> rnd.Intn(64)
+		if i < 500 {
+			x &= 0xfffff
+		}
+		p.Append(x)
+		expected = append(expected, x)
+	}
+
+	require.Equal(t, len(expected), p.Len())
+	d := make([]uint64, p.Len()+10)
+	require.Equal(t, p.Len(), p.Unpack(d))
+	require.Equal(t, expected, d[:p.Len()])
+	require.Equal(t, 10, p.Unpack(d[:10]))
+}
@@ -0,0 +1,132 @@
+package densebits
+
+import "math/bits"
+
+// SetRange sets all bits in the range [lo, hi) to one.
+// The complexity is O(n).
+func (s Set) SetRange(lo, hi int) {
+	wlo, whi, mlo, mhi, ok := rangemasks(lo, hi)
+	if !ok {
+		return
+	}
+	s[wlo] |= mlo
+	if wlo == whi {
+		return
+	}
+	for i := wlo + 1; i < whi; i++ {
+		s[i] = ^uint64(0)
+	}
+	s[whi] |= mhi
+}
+
+// ClearRange sets all bits in the range [lo, hi) to zero.
+// The complexity is O(n).
+func (s Set) ClearRange(lo, hi int) {
+	wlo, whi, mlo, mhi, ok := rangemasks(lo, hi)
+	if !ok {
+		return
+	}
+	s[wlo] &^= mlo
+	if wlo == whi {
+		return
+	}
+	for i := wlo + 1; i < whi; i++ {
+		s[i] = 0
+	}
+	s[whi] &^= mhi
+}
+
+// FlipRange flips all bits in the range [lo, hi).
+// The complexity is O(n).
+func (s Set) FlipRange(lo, hi int) {
+	wlo, whi, mlo, mhi, ok := rangemasks(lo, hi)
+	if !ok {
+		return
+	}
+	s[wlo] ^= mlo
+	if wlo == whi {
+		return
+	}
+	for i := wlo + 1; i < whi; i++ {
+		s[i] = ^s[i]
+	}
+	s[whi] ^= mhi
+}
+
+// CountRange reports the number of one bits in the range [lo, hi).
+// The complexity is O(n).
+func (s Set) CountRange(lo, hi int) int {
+	wlo, whi, mlo, mhi, ok := rangemasks(lo, hi)
+	if !ok {
+		return 0
+	}
+	count := bits.OnesCount64(s[wlo] & mlo)
+	if wlo == whi {
+		return count
+	}
+	for i := wlo + 1; i < whi; i++ {
+		count += bits.OnesCount64(s[i])
+	}
+	return count + bits.OnesCount64(s[whi]&mhi)
+}
+
+// AllRange reports whether all bits in the range [lo, hi) are set to one.
+// An empty range reports true.
+// The complexity is O(n).
+func (s Set) AllRange(lo, hi int) bool {
+	wlo, whi, mlo, mhi, ok := rangemasks(lo, hi)
+	if !ok {
+		return true
+	}
+	if s[wlo]&mlo != mlo {
+		return false
+	}
+	if wlo == whi {
+		return true
+	}
+	for i := wlo + 1; i < whi; i++ {
+		if s[i] != ^uint64(0) {
+			return false
+		}
+	}
+	return s[whi]&mhi == mhi
+}
+
+// AnyRange reports whether any bit in the range [lo, hi) is set to one.
+// An empty range reports false.
+// The complexity is O(n).
+func (s Set) AnyRange(lo, hi int) bool {
+	wlo, whi, mlo, mhi, ok := rangemasks(lo, hi)
+	if !ok {
+		return false
+	}
+	if s[wlo]&mlo != 0 {
+		return true
+	}
+	if wlo == whi {
+		return false
+	}
+	for i := wlo + 1; i < whi; i++ {
+		if s[i] != 0 {
+			return true
+		}
+	}
+	return s[whi]&mhi != 0
+}
+
+// rangemasks returns the first and last word indexes of the range [lo, hi)
+// together with the masks selecting the bits of the range in those words.
+// If both indexes are equal, mlo holds the combined mask.
+// Reports false if the range is empty.
+func rangemasks(lo, hi int) (wlo, whi int, mlo, mhi uint64, ok bool) {
+	if lo >= hi {
+		return 0, 0, 0, 0, false
+	}
+	wlo, whi = lo/64, (hi-1)/64
+	mlo = ^uint64(0) << (lo % 64)
+	mhi = ^uint64(0) >> (63 - (hi-1)%64)
+	if wlo == whi {
+		mlo &= mhi
+	}
+	return wlo, whi, mlo, mhi, true
+}
@@ -0,0 +1,111 @@
+package densebits
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestRangeSetClear(t *testing.T) {
+	a := New(256)
+
+	a.SetRange(3, 200)
+	require.Equal(t, 197, a.OnesCount())
+	require.True(t, !a.Get(2))
+	require.True(t, a.Get(3))
+	require.True(t, a.Get(199))
+	require.True(t, !a.Get(200))
+
+	a.ClearRange(10, 20)
+	require.Equal(t, 187, a.OnesCount())
+	require.True(t, a.Get(9))
+	require.True(t, !a.Get(10))
+	require.True(t, !a.Get(19))
+	require.True(t, a.Get(20))
+
+	a.ClearRange(0, a.Len())
+	require.Equal(t, 0, a.OnesCount())
+
+	a.SetRange(0, a.Len())
+	require.Equal(t, a.Len(), a.OnesCount())
+
+	a.SetRange(5, 5)
+	a.ClearRange(5, 4)
+	require.Equal(t, a.Len(), a.OnesCount())
+}
+
+func TestRangeFlipCount(t *testing.T) {
+	a := New(256)
+	a.FlipRange(60, 70)
+	require.Equal(t, 10, a.OnesCount())
+	require.Equal(t, 10, a.CountRange(0, a.Len()))
+	require.Equal(t, 4, a.CountRange(0, 64))
+	require.Equal(t, 6, a.CountRange(64, 128))
+	require.Equal(t, 2, a.CountRange(62, 64))
+	require.Equal(t, 0, a.CountRange(70, 70))
+
+	a.FlipRange(0, a.Len())
+	require.Equal(t, a.Len()-10, a.OnesCount())
+}
+
+func TestRangeAllAny(t *testing.T) {
+	a := New(256)
+	require.True(t, !a.AnyRange(0, a.Len()))
+	require.True(t, a.AllRange(7, 7))
+	require.True(t, !a.AnyRange(7, 7))
+
+	a.SetRange(50, 150)
+	require.True(t, a.AllRange(50, 150))
+	require.True(t, !a.AllRange(49, 150))
+	require.True(t, !a.AllRange(50, 151))
+	require.True(t, a.AnyRange(0, 51))
+	require.True(t, !a.AnyRange(0, 50))
+	require.True(t, a.AnyRange(149, 256))
+	require.True(t, !a.AnyRange(150, 256))
+}
+
+func TestRangeRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	a := New(512)
+	b := make([]bool, a.Len())
+
+	for n := 0; n < 1000; n++ {
+		lo := rnd.Intn(a.Len())
+		hi := lo + rnd.Intn(a.Len()-lo+1)
+
+		switch rnd.Intn(3) {
+		case 0:
+			a.SetRange(lo, hi)
+			for i := lo; i < hi; i++ {
+				b[i] = true
+			}
+		case 1:
+			a.ClearRange(lo, hi)
+			for i := lo; i < hi; i++ {
+				b[i] = false
+			}
+		case 2:
+			a.FlipRange(lo, hi)
+			for i := lo; i < hi; i++ {
+				b[i] = !b[i]
+			}
+		}
+
+		lo = rnd.Intn(a.Len())
+		hi = lo + rnd.Intn(a.Len()-lo+1)
+		count := 0
+		for i := lo; i < hi; i++ {
+			if b[i] {
+				count++
+			}
+		}
+		require.Equal(t, count, a.CountRange(lo, hi))
+		require.Equal(t, count == hi-lo, a.AllRange(lo, hi))
+		require.Equal(t, count > 0, a.AnyRange(lo, hi))
+	}
+
+	for i := range b {
+		require.Equal(t, b[i], a.Get(i))
+	}
+}
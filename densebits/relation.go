@@ -0,0 +1,81 @@
+package densebits
+
+import "math/bits"
+
+// The relational methods below do not allocate memory.
+// If the sets have different lengths, the shorter set
+// is treated as if it were extended with zero bits.
+
+// IsSubsetOf reports whether every one bit in s is also set in p.
+// The complexity is O(n).
+func (s Set) IsSubsetOf(p Set) bool {
+	m := min(len(s), len(p))
+	for i := 0; i < m; i++ {
+		if s[i]&^p[i] != 0 {
+			return false
+		}
+	}
+	for _, x := range s[m:] {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects reports whether s and p have at least one one bit in common.
+// The complexity is O(n).
+func (s Set) Intersects(p Set) bool {
+	m := min(len(s), len(p))
+	for i := 0; i < m; i++ {
+		if s[i]&p[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectionCount reports the number of one bits in s AND p.
+// The complexity is O(n).
+func (s Set) IntersectionCount(p Set) int {
+	count := 0
+	m := min(len(s), len(p))
+	for i := 0; i < m; i++ {
+		count += bits.OnesCount64(s[i] & p[i])
+	}
+	return count
+}
+
+// UnionCount reports the number of one bits in s OR p.
+// The complexity is O(n).
+func (s Set) UnionCount(p Set) int {
+	count := 0
+	m := min(len(s), len(p))
+	for i := 0; i < m; i++ {
+		count += bits.OnesCount64(s[i] | p[i])
+	}
+	return count + s[m:].OnesCount() + p[m:].OnesCount()
+}
+
+// DifferenceCount reports the number of one bits in s AND NOT p.
+// The complexity is O(n).
+func (s Set) DifferenceCount(p Set) int {
+	count := 0
+	m := min(len(s), len(p))
+	for i := 0; i < m; i++ {
+		count += bits.OnesCount64(s[i] &^ p[i])
+	}
+	return count + s[m:].OnesCount()
+}
+
+// Hamming reports the Hamming distance between s and p,
+// which is the number of one bits in s XOR p.
+// The complexity is O(n).
+func (s Set) Hamming(p Set) int {
+	count := 0
+	m := min(len(s), len(p))
+	for i := 0; i < m; i++ {
+		count += bits.OnesCount64(s[i] ^ p[i])
+	}
+	return count + s[m:].OnesCount() + p[m:].OnesCount()
+}
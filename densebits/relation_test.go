@@ -0,0 +1,64 @@
+package densebits
+
+import (
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestRelationSubset(t *testing.T) {
+	a := New(128)
+	b := New(256)
+	a.SetRange(10, 20)
+	b.SetRange(0, 100)
+
+	require.True(t, a.IsSubsetOf(b))
+	require.True(t, !b.IsSubsetOf(a))
+	require.True(t, Set{}.IsSubsetOf(a))
+	require.True(t, a.IsSubsetOf(a))
+
+	b.Reset()
+	b.SetRange(10, 20)
+	b.Set(200, true)
+	require.True(t, a.IsSubsetOf(b))
+	require.True(t, !b.IsSubsetOf(a))
+}
+
+func TestRelationIntersects(t *testing.T) {
+	a := New(128)
+	b := New(256)
+	a.SetRange(0, 64)
+	b.SetRange(64, 256)
+	require.True(t, !a.Intersects(b))
+	require.True(t, !b.Intersects(a))
+	require.Equal(t, 0, a.IntersectionCount(b))
+
+	a.Set(100, true)
+	require.True(t, a.Intersects(b))
+	require.True(t, b.Intersects(a))
+	require.Equal(t, 1, a.IntersectionCount(b))
+	require.Equal(t, 1, b.IntersectionCount(a))
+}
+
+func TestRelationCounts(t *testing.T) {
+	a := New(128)
+	b := New(256)
+	a.SetRange(0, 100)
+	b.SetRange(50, 200)
+
+	var c Set
+
+	c.And(a, b)
+	require.Equal(t, c.OnesCount(), a.IntersectionCount(b))
+	require.Equal(t, 50, a.IntersectionCount(b))
+
+	require.Equal(t, 200, a.UnionCount(b))
+	require.Equal(t, 200, b.UnionCount(a))
+
+	require.Equal(t, 50, a.DifferenceCount(b))
+	require.Equal(t, 100, b.DifferenceCount(a))
+
+	require.Equal(t, 150, a.Hamming(b))
+	require.Equal(t, 150, b.Hamming(a))
+	require.Equal(t, 0, a.Hamming(a))
+}
@@ -0,0 +1,179 @@
+package disjoint
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// References:
+// Wait-free Parallel Algorithms for the Union-Find Problem (Anderson and Woll)
+// Concurrent Disjoint Set Union (Jayanti and Tarjan)
+
+// Concurrent is a disjoint set that is safe to use concurrently.
+// Every node stores the index of its parent and roots point to themselves.
+// Parent links are updated with atomic compare-and-swap
+// so that Union, Find and Same are lock-free and linearizable.
+//
+// Roots are linked by a fixed pseudo-random priority instead of by size,
+// and Find shortens paths by path splitting.
+//
+// Operations that inspect the whole set, such as CountGroups and Snapshot,
+// do not observe a consistent view while other goroutines are modifying it.
+type Concurrent []uint64
+
+// NewConcurrent creates a concurrent disjoint set containing n singleton groups.
+func NewConcurrent(n int) Concurrent {
+	s := make(Concurrent, n)
+	s.Reset()
+	return s
+}
+
+// Reset clears the set to have Len() singleton groups.
+// Reset is not safe to call concurrently with other methods.
+// The complexity is O(n).
+func (s Concurrent) Reset() {
+	for i := range s {
+		s[i] = uint64(i)
+	}
+}
+
+// priority returns a pseudo-random but fixed priority of node i.
+func priority(i uint64) uint64 {
+	i ^= i >> 33
+	i *= 0xff51afd7ed558ccd
+	i ^= i >> 33
+	i *= 0xc4ceb9fe1a85ec53
+	i ^= i >> 33
+	return i
+}
+
+// lowerPriority reports whether root p should be linked below root q.
+func lowerPriority(p, q uint64) bool {
+	pp, pq := priority(p), priority(q)
+	return pp < pq || pp == pq && p < q
+}
+
+func (s Concurrent) find(i uint64) uint64 {
+	for {
+		p := atomic.LoadUint64(&s[i])
+		if p == i {
+			return i
+		}
+		g := atomic.LoadUint64(&s[p])
+		if g != p { // path splitting
+			atomic.CompareAndSwapUint64(&s[i], p, g)
+		}
+		i = p
+	}
+}
+
+// Find returns the root of i.
+// The root may change as soon as Find returns
+// if other goroutines are merging groups.
+// The complexity is O(log(n)) with high probability.
+func (s Concurrent) Find(i int) int {
+	return int(s.find(uint64(i)))
+}
+
+// Union merges the groups of i and j together.
+// Returns true if the groups were merged or false if
+// i and j are already in the same group.
+// The complexity is O(log(n)) with high probability.
+func (s Concurrent) Union(i, j int) bool {
+	p, q := uint64(i), uint64(j)
+	for {
+		p, q = s.find(p), s.find(q)
+		if p == q {
+			return false
+		}
+		if !lowerPriority(p, q) {
+			p, q = q, p
+		}
+		// p is still a root only if no other goroutine linked it meanwhile
+		if atomic.CompareAndSwapUint64(&s[p], p, q) {
+			return true
+		}
+	}
+}
+
+// Same reports whether nodes i and j are in the same group.
+// The complexity is O(log(n)) with high probability.
+func (s Concurrent) Same(i, j int) bool {
+	p, q := uint64(i), uint64(j)
+	for {
+		p, q = s.find(p), s.find(q)
+		if p == q {
+			return true
+		}
+		// p and q were in different groups at the moment that
+		// p was observed to still be a root
+		if atomic.LoadUint64(&s[p]) == p {
+			return false
+		}
+	}
+}
+
+// Len reports the number of nodes in s.
+func (s Concurrent) Len() int {
+	return len(s)
+}
+
+// CountGroups reports the number of groups in s.
+// The complexity is O(n).
+func (s Concurrent) CountGroups() int {
+	n := 0
+	for i := range s {
+		if atomic.LoadUint64(&s[i]) == uint64(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// Snapshot copies the groups of s to d,
+// which will be resized to s.Len() nodes if needed.
+// The groups are the same but the roots may differ.
+// The complexity is O(n log(n)) with high probability.
+func (s Concurrent) Snapshot(d *Set) {
+	if cap(*d) < len(s) {
+		*d = make(Set, len(s))
+	}
+	*d = (*d)[:len(s)]
+	d.Reset()
+	for i := range s {
+		d.Union(i, s.Find(i))
+	}
+}
+
+// ParallelUnion merges the groups of the endpoints of every edge
+// by partitioning edges into contiguous chunks
+// that are processed concurrently by the given number of goroutines.
+// If workers is zero or negative, it defaults to runtime.GOMAXPROCS(0).
+// Returns the number of unions that merged groups.
+func (s Concurrent) ParallelUnion(edges [][2]int, workers int) int {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(1, min(workers, len(edges)))
+
+	var wg sync.WaitGroup
+	var merged atomic.Int64
+	chunk := (len(edges) + workers - 1) / workers
+	for lo := 0; lo < len(edges); lo += chunk {
+		wg.Add(1)
+		go func(edges [][2]int) {
+			defer wg.Done()
+			n := 0
+			for _, e := range edges {
+				if s.Union(e[0], e[1]) {
+					n++
+				}
+			}
+			merged.Add(int64(n))
+		}(edges[lo:min(lo+chunk, len(edges))])
+	}
+	wg.Wait()
+
+	return int(merged.Load())
+}
@@ -0,0 +1,77 @@
+package disjoint
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestConcurrent(t *testing.T) {
+	set := NewConcurrent(8)
+	require.True(t, set.Union(0, 1))
+	require.True(t, set.Union(2, 1))
+	require.True(t, !set.Union(0, 2))
+	require.True(t, set.Union(3, 4))
+
+	require.Equal(t, 8, set.Len())
+	require.Equal(t, 5, set.CountGroups())
+	require.True(t, set.Same(0, 2))
+	require.True(t, !set.Same(0, 3))
+	require.Equal(t, set.Find(0), set.Find(2))
+
+	var d Set
+	set.Snapshot(&d)
+	require.Equal(t, 5, d.CountGroups())
+	require.Equal(t, 3, d.Size(2))
+	require.Equal(t, 2, d.Size(4))
+
+	set.Reset()
+	require.Equal(t, 8, set.CountGroups())
+}
+
+func TestConcurrentParallel(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const n = 10000
+	edges := make([][2]int, 8000)
+	for i := range edges {
+		edges[i] = [2]int{rnd.Intn(n), rnd.Intn(n)}
+	}
+
+	ref := New(n)
+	merged := 0
+	for _, e := range edges {
+		if ref.Union(e[0], e[1]) {
+			merged++
+		}
+	}
+
+	set := NewConcurrent(n)
+	require.Equal(t, merged, set.ParallelUnion(edges, 0))
+	require.Equal(t, ref.CountGroups(), set.CountGroups())
+
+	labels := ref.Labels()
+	var mismatches atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			for k := 0; k < 1000; k++ {
+				i, j := rnd.Intn(n), rnd.Intn(n)
+				if (labels[i] == labels[j]) != set.Same(i, j) {
+					mismatches.Add(1)
+				}
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+	require.Equal(t, int64(0), mismatches.Load())
+
+	require.Equal(t, merged, NewConcurrent(n).ParallelUnion(edges, 3))
+	require.Equal(t, 0, set.ParallelUnion(nil, 0))
+}
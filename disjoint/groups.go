@@ -0,0 +1,88 @@
+package disjoint
+
+// root returns the root of i assuming that s is fully compressed,
+// such that every node either is a root or links directly to one.
+func (s Set) root(i int) int {
+	if s[i] > 0 {
+		return i
+	}
+	return -s[i]
+}
+
+// compress links every node directly to the root of its group.
+func (s Set) compress() {
+	for i := range s {
+		s.Find(i)
+	}
+}
+
+// Groups collects the members of every group into subslices,
+// in the same style as xslices.Group.
+// Groups are ordered by their smallest member
+// and the members of every group are in increasing order.
+// The members of all groups share a single backing slice of length Len().
+// The complexity is O(n).
+func (s Set) Groups() [][]int {
+	s.compress()
+
+	n := len(s)
+	members := make([]int, n)
+	groups := make([][]int, 0, s.CountGroups())
+
+	// Temporarily mark the root of the g-th group with n+1+g.
+	// Marks are always greater than the size of any group,
+	// so roots remain distinguishable from links.
+	off := 0
+	for i := range s {
+		r := s.root(i)
+		if size := s[r]; size <= n {
+			groups = append(groups, members[off:off:off+size])
+			s[r] = n + len(groups)
+			off += size
+		}
+		g := s[r] - n - 1
+		groups[g] = append(groups[g], i)
+	}
+
+	// restore the sizes
+	for _, g := range groups {
+		s[s.root(g[0])] = len(g)
+	}
+
+	return groups
+}
+
+// Labels assigns to every node the dense label 0 <= k < CountGroups()
+// of its group, such that Labels()[i] is the index of the group of i
+// in the result of Groups.
+// The complexity is O(n).
+func (s Set) Labels() []int {
+	labels := make([]int, len(s))
+	for i := range labels {
+		labels[i] = -1
+	}
+
+	k := 0
+	for i := range s {
+		r := s.Find(i)
+		if labels[r] < 0 {
+			labels[r] = k
+			k++
+		}
+		labels[i] = labels[r]
+	}
+
+	return labels
+}
+
+// Members calls f for every member of the group of i in increasing order.
+// If f returns false, Members stops the iteration.
+// The complexity is O(n).
+func (s Set) Members(i int, f func(j int) bool) {
+	r := s.Find(i)
+	for j := range s {
+		if s.Find(j) == r && !f(j) {
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package disjoint
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestGroups(t *testing.T) {
+	set := New(8)
+	set.Union(3, 1)
+	set.Union(1, 6)
+	set.Union(7, 2)
+	set.Union(5, 4)
+	set.Union(4, 7)
+
+	groups := set.Groups()
+	require.Equal(t, [][]int{{0}, {1, 3, 6}, {2, 4, 5, 7}}, groups)
+	require.Equal(t, []int{0, 1, 2, 1, 2, 2, 1, 2}, set.Labels())
+
+	// sizes are intact
+	require.Equal(t, 3, set.Size(6))
+	require.Equal(t, 4, set.Size(2))
+	require.Equal(t, 3, set.CountGroups())
+
+	// groups do not overlap
+	groups[0] = append(groups[0], 99)
+	require.Equal(t, []int{1, 3, 6}, groups[1])
+
+	var members []int
+	set.Members(5, func(j int) bool {
+		members = append(members, j)
+		return true
+	})
+	require.Equal(t, []int{2, 4, 5, 7}, members)
+
+	members = members[:0]
+	set.Members(5, func(j int) bool {
+		members = append(members, j)
+		return len(members) < 2
+	})
+	require.Equal(t, []int{2, 4}, members)
+
+	require.Equal(t, 0, len(New(0).Groups()))
+}
+
+func TestGroupsRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const n = 1000
+	set := New(n)
+	for k := 0; k < 700; k++ {
+		set.Union(rnd.Intn(n), rnd.Intn(n))
+	}
+
+	ref := append(Set(nil), set...)
+	labels := set.Labels()
+	groups := set.Groups()
+	require.Equal(t, ref.CountGroups(), len(groups))
+
+	seen := 0
+	for g, members := range groups {
+		require.Equal(t, ref.Size(members[0]), len(members))
+		for k, j := range members {
+			require.Equal(t, g, labels[j])
+			require.True(t, ref.Same(members[0], j))
+			require.Equal(t, ref.Find(j), set.Find(j))
+			require.True(t, k == 0 || members[k-1] < j)
+		}
+		seen += len(members)
+	}
+	require.Equal(t, n, seen)
+}
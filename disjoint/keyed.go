@@ -0,0 +1,143 @@
+package disjoint
+
+// Keyed is a disjoint set over keys of any comparable type.
+// Keys are assigned consecutive indexes in the order that they are first seen
+// and the underlying [Set] grows as new keys are added.
+// Keys that have not been added behave as singleton groups.
+// The zero value is an empty set ready to use.
+type Keyed[K comparable] struct {
+	set   Set
+	keys  []K
+	index map[K]int
+}
+
+// NewKeyed creates an empty keyed disjoint set
+// with room for n keys before it must grow.
+func NewKeyed[K comparable](n int) *Keyed[K] {
+	return &Keyed[K]{
+		set:   make(Set, 0, n),
+		keys:  make([]K, 0, n),
+		index: make(map[K]int, n),
+	}
+}
+
+// Reset removes all keys from s.
+// The complexity is O(n).
+func (s *Keyed[K]) Reset() {
+	s.set = s.set[:0]
+	clear(s.keys)
+	s.keys = s.keys[:0]
+	clear(s.index)
+}
+
+// Add adds k as a singleton group if it has not been seen before
+// and returns its index.
+// The complexity is amortised O(1).
+func (s *Keyed[K]) Add(k K) int {
+	if i, ok := s.index[k]; ok {
+		return i
+	}
+	if s.index == nil {
+		s.index = make(map[K]int)
+	}
+	i := len(s.keys)
+	s.index[k] = i
+	s.keys = append(s.keys, k)
+	s.set = append(s.set, 1)
+	return i
+}
+
+// Index returns the index of k and reports whether k has been added.
+func (s *Keyed[K]) Index(k K) (int, bool) {
+	i, ok := s.index[k]
+	return i, ok
+}
+
+// Key returns the key at index i.
+func (s *Keyed[K]) Key(i int) K {
+	return s.keys[i]
+}
+
+// Find returns the key at the root of the group of k.
+// Returns k itself if k has not been added.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (s *Keyed[K]) Find(k K) K {
+	if i, ok := s.index[k]; ok {
+		return s.keys[s.set.Find(i)]
+	}
+	return k
+}
+
+// Union adds a and b if they have not been seen before
+// and merges their groups together.
+// Returns true if the groups were merged or false if
+// a and b are already in the same group.
+// The complexity is amortised O(α(n)), which is O(1) for all practical purposes.
+func (s *Keyed[K]) Union(a, b K) bool {
+	i := s.Add(a)
+	j := s.Add(b)
+	return s.set.Union(i, j)
+}
+
+// Same reports whether keys a and b are in the same group.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (s *Keyed[K]) Same(a, b K) bool {
+	i, iok := s.index[a]
+	j, jok := s.index[b]
+	if !iok || !jok {
+		return a == b
+	}
+	return s.set.Same(i, j)
+}
+
+// Len reports the number of keys in s.
+func (s *Keyed[K]) Len() int {
+	return len(s.keys)
+}
+
+// Size reports the size of the group of k.
+// Returns 1 if k has not been added.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (s *Keyed[K]) Size(k K) int {
+	if i, ok := s.index[k]; ok {
+		return s.set.Size(i)
+	}
+	return 1
+}
+
+// CountGroups reports the number of groups in s.
+// The complexity is O(n).
+func (s *Keyed[K]) CountGroups() int {
+	return s.set.CountGroups()
+}
+
+// Groups collects the keys of every group into subslices.
+// Groups are ordered by the key that was added first
+// and the keys of every group are in the order that they were added.
+// The keys of all groups share a single backing slice of length Len().
+// The complexity is O(n).
+func (s *Keyed[K]) Groups() [][]K {
+	groups := s.set.Groups()
+	keys := make([]K, 0, len(s.keys))
+	d := make([][]K, len(groups))
+	for g, members := range groups {
+		off := len(keys)
+		for _, i := range members {
+			keys = append(keys, s.keys[i])
+		}
+		d[g] = keys[off:len(keys):len(keys)]
+	}
+	return d
+}
+
+// GroupMap returns the keys of every group keyed by the root key of that group,
+// such that GroupMap()[Find(k)] contains k.
+// The complexity is O(n).
+func (s *Keyed[K]) GroupMap() map[K][]K {
+	groups := s.Groups()
+	m := make(map[K][]K, len(groups))
+	for _, keys := range groups {
+		m[s.Find(keys[0])] = keys
+	}
+	return m
+}
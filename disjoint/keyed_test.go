@@ -0,0 +1,59 @@
+package disjoint
+
+import (
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestKeyed(t *testing.T) {
+	var set Keyed[string]
+	require.True(t, set.Union("b", "a"))
+	require.True(t, set.Union("c", "d"))
+	require.True(t, set.Union("e", "a"))
+	require.True(t, !set.Union("a", "b"))
+	require.Equal(t, 5, set.Add("f"))
+	require.Equal(t, 0, set.Add("b"))
+
+	require.Equal(t, 6, set.Len())
+	require.Equal(t, 3, set.CountGroups())
+	require.Equal(t, 3, set.Size("e"))
+	require.Equal(t, 1, set.Size("x"))
+	require.True(t, set.Same("b", "e"))
+	require.True(t, !set.Same("b", "c"))
+	require.True(t, set.Same("x", "x"))
+	require.True(t, !set.Same("x", "a"))
+	require.Equal(t, "x", set.Find("x"))
+	require.Equal(t, set.Find("a"), set.Find("e"))
+
+	i, ok := set.Index("d")
+	require.True(t, ok)
+	require.Equal(t, "d", set.Key(i))
+	_, ok = set.Index("x")
+	require.True(t, !ok)
+
+	require.Equal(t, [][]string{{"b", "a", "e"}, {"c", "d"}, {"f"}}, set.Groups())
+
+	m := set.GroupMap()
+	require.Equal(t, 3, len(m))
+	require.Equal(t, []string{"c", "d"}, m[set.Find("d")])
+	require.Equal(t, []string{"f"}, m["f"])
+
+	set.Reset()
+	require.Equal(t, 0, set.Len())
+	require.Equal(t, 0, len(set.Groups()))
+}
+
+func TestKeyedStruct(t *testing.T) {
+	type point struct{ x, y int }
+	set := NewKeyed[point](4)
+	for x := 0; x < 10; x++ {
+		set.Union(point{x, 0}, point{x + 1, 0})
+		set.Union(point{x, 5}, point{x + 1, 5})
+	}
+	require.Equal(t, 22, set.Len())
+	require.Equal(t, 2, set.CountGroups())
+	require.True(t, set.Same(point{0, 0}, point{10, 0}))
+	require.True(t, !set.Same(point{0, 0}, point{0, 5}))
+	require.Equal(t, 11, set.Size(point{3, 5}))
+}
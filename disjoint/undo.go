@@ -0,0 +1,136 @@
+package disjoint
+
+// Undoable is a disjoint set whose unions can be undone,
+// which is useful for backtracking search.
+// It uses union by size without path compression,
+// so that every union changes exactly two nodes that are recorded in a log.
+// Its methods have the same semantics as those of [Set].
+//
+// Use Checkpoint to mark the current state and Rollback to return to it:
+//
+//	cp := s.Checkpoint()
+//	s.Union(1, 2)
+//	s.Rollback(cp) // 1 and 2 are no longer in the same group
+type Undoable struct {
+	set    Set
+	log    []undoEntry
+	groups int
+}
+
+type undoEntry struct {
+	root  int
+	child int
+	size  int
+}
+
+// NewUndoable creates an undoable disjoint set containing n singleton groups.
+func NewUndoable(n int) *Undoable {
+	return &Undoable{
+		set:    New(n),
+		groups: n,
+	}
+}
+
+// Reset clears the set to have Len() singleton groups
+// and discards the log.
+// The complexity is O(n).
+func (s *Undoable) Reset() {
+	s.set.Reset()
+	s.log = s.log[:0]
+	s.groups = len(s.set)
+}
+
+// Find returns the root of i.
+// The complexity is O(log(n)).
+func (s *Undoable) Find(i int) int {
+	for {
+		switch val := s.set[i]; {
+		case val > 0:
+			return i
+		case val == 0 && i == 0:
+			panic("disjoint: node 0 points to itself")
+		default:
+			i = -val
+		}
+	}
+}
+
+// Union merges the groups of i and j together
+// whereby the smaller group is merged into the larger.
+// Returns true if the groups were merged or false if
+// i and j are already in the same group.
+// Only unions that merge groups are recorded in the log.
+// The complexity is O(log(n)).
+func (s *Undoable) Union(i, j int) bool {
+	p := s.Find(i)
+	q := s.Find(j)
+
+	if p == q {
+		return false
+	}
+
+	// union by size
+	if s.set[q] >= s.set[p] {
+		p, q = q, p
+	}
+
+	s.log = append(s.log, undoEntry{root: p, child: q, size: s.set[q]})
+	s.set[p] += s.set[q]
+	s.set[q] = -p
+	s.groups--
+	return true
+}
+
+// Same reports whether nodes i and j are in the same group.
+// The complexity is O(log(n)).
+func (s *Undoable) Same(i, j int) bool {
+	return s.Find(i) == s.Find(j)
+}
+
+// Len reports the number of nodes in s.
+func (s *Undoable) Len() int {
+	return len(s.set)
+}
+
+// Size reports the size of the group of i.
+// The complexity is O(log(n)).
+func (s *Undoable) Size(i int) int {
+	return s.set[s.Find(i)]
+}
+
+// CountGroups reports the number of groups in s.
+// The complexity is O(1).
+func (s *Undoable) CountGroups() int {
+	return s.groups
+}
+
+// Checkpoint returns a marker of the current state
+// that can be passed to Rollback and UnionsSince.
+// The complexity is O(1).
+func (s *Undoable) Checkpoint() int {
+	return len(s.log)
+}
+
+// Rollback undoes all unions made since the checkpoint to.
+// Checkpoints taken after to become invalid.
+// Panics if to is not a valid checkpoint.
+// The complexity is O(k) where k is the number of undone unions.
+func (s *Undoable) Rollback(to int) {
+	if to < 0 || to > len(s.log) {
+		panic("disjoint: invalid checkpoint")
+	}
+	for k := len(s.log) - 1; k >= to; k-- {
+		e := s.log[k]
+		s.set[e.root] -= e.size
+		s.set[e.child] = e.size
+	}
+	s.groups += len(s.log) - to
+	s.log = s.log[:to]
+}
+
+// UnionsSince reports the number of unions that merged groups
+// since the checkpoint cp.
+// The complexity is O(1).
+func (s *Undoable) UnionsSince(cp int) int {
+	return len(s.log) - cp
+}
@@ -0,0 +1,108 @@
+package disjoint
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestUndoable(t *testing.T) {
+	set := NewUndoable(8)
+	set.Union(0, 0)
+	set.Union(0, 1)
+	set.Union(0, 2)
+	set.Union(3, 3)
+	set.Union(3, 4)
+	set.Union(5, 5)
+	set.Union(5, 6)
+	set.Union(7, 7)
+
+	require.Equal(t, 8, set.Len(), "Len")
+	require.Equal(t, 4, set.CountGroups(), "CountGroups")
+	require.True(t, set.Same(0, 1), "Same")
+	require.True(t, !set.Same(0, 7), "not Same")
+
+	roots := []int{1, 1, 1, 4, 4, 6, 6, 7}
+	sizes := []int{3, 3, 3, 2, 2, 2, 2, 1}
+
+	for i := 0; i < set.Len(); i++ {
+		require.Equal(t, roots[i], set.Find(i), "Find", i)
+		require.Equal(t, sizes[i], set.Size(i), "Size", i)
+	}
+}
+
+func TestUndoableRollback(t *testing.T) {
+	set := NewUndoable(6)
+	set.Union(0, 1)
+
+	cp := set.Checkpoint()
+	require.True(t, set.Union(2, 3))
+	require.True(t, set.Union(0, 2))
+	require.True(t, !set.Union(1, 3))
+	require.Equal(t, 2, set.UnionsSince(cp))
+	require.Equal(t, 4, set.Size(3))
+	require.Equal(t, 3, set.CountGroups())
+
+	inner := set.Checkpoint()
+	set.Union(4, 5)
+	require.Equal(t, 2, set.CountGroups())
+	set.Rollback(inner)
+	require.True(t, !set.Same(4, 5))
+	require.Equal(t, 3, set.CountGroups())
+
+	set.Rollback(cp)
+	require.Equal(t, 0, set.UnionsSince(cp))
+	require.True(t, set.Same(0, 1))
+	require.True(t, !set.Same(0, 2))
+	require.True(t, !set.Same(2, 3))
+	require.Equal(t, 2, set.Size(0))
+	require.Equal(t, 1, set.Size(3))
+	require.Equal(t, 5, set.CountGroups())
+
+	set.Rollback(0)
+	require.Equal(t, 6, set.CountGroups())
+
+	var panicked bool
+	func() {
+		defer func() { panicked = recover() != nil }()
+		set.Rollback(1)
+	}()
+	require.True(t, panicked)
+}
+
+func TestUndoableStress(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const n = 2000
+	set := NewUndoable(n)
+	ref := New(n)
+
+	var cps []int
+	var refs []Set
+	for k := 0; k < 5000; k++ {
+		switch r := rnd.Intn(10); {
+		case r == 0:
+			cps = append(cps, set.Checkpoint())
+			refs = append(refs, append(Set(nil), ref...))
+		case r == 1 && len(cps) > 0:
+			m := rnd.Intn(len(cps))
+			set.Rollback(cps[m])
+			ref = refs[m]
+			cps, refs = cps[:m], refs[:m]
+		default:
+			i, j := rnd.Intn(n), rnd.Intn(n)
+			require.Equal(t, ref.Union(i, j), set.Union(i, j))
+		}
+	}
+
+	require.Equal(t, ref.CountGroups(), set.CountGroups())
+	for i := 0; i < n; i++ {
+		require.Equal(t, ref.Size(i), set.Size(i))
+		j := rnd.Intn(n)
+		require.Equal(t, ref.Same(i, j), set.Same(i, j))
+	}
+
+	set.Reset()
+	require.Equal(t, n, set.CountGroups())
+	require.Equal(t, 0, set.Checkpoint())
+}
@@ -0,0 +1,165 @@
+package disjoint
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Group defines an abelian group over potentials of type T.
+// Op must be associative and commutative,
+// Identity must be its identity element
+// and Inverse must return the inverse of its argument.
+type Group[T any] struct {
+	Identity T
+	Op       func(a, b T) T
+	Inverse  func(a T) T
+}
+
+// Additive returns the group of integers under addition.
+// Use it to track relations such as x = y + d.
+func Additive[T Integer]() Group[T] {
+	return Group[T]{
+		Op:      func(a, b T) T { return a + b },
+		Inverse: func(a T) T { return -a },
+	}
+}
+
+// Xor returns the group of integers under bitwise exclusive or.
+// Use it to track parity relations such as x != y with d = 1.
+func Xor[T Integer]() Group[T] {
+	return Group[T]{
+		Op:      func(a, b T) T { return a ^ b },
+		Inverse: func(a T) T { return a },
+	}
+}
+
+// Weighted is a disjoint set that also tracks the relative potential
+// between nodes of the same group.
+// Every node stores its potential relative to its parent,
+// and potentials are composed along paths under the group operation.
+// Its methods have the same semantics as those of [Set].
+type Weighted[T comparable] struct {
+	set Set
+	pot []T
+	g   Group[T]
+}
+
+// NewWeighted creates a weighted disjoint set containing n singleton groups
+// using the additive group of integers.
+func NewWeighted(n int) *Weighted[int] {
+	return NewWeightedGroup(n, Additive[int]())
+}
+
+// NewWeightedGroup creates a weighted disjoint set containing n singleton groups
+// using the group g.
+func NewWeightedGroup[T comparable](n int, g Group[T]) *Weighted[T] {
+	w := &Weighted[T]{
+		set: New(n),
+		pot: make([]T, n),
+		g:   g,
+	}
+	w.Reset()
+	return w
+}
+
+// Reset clears the set to have Len() singleton groups.
+// The complexity is O(n).
+func (w *Weighted[T]) Reset() {
+	w.set.Reset()
+	for i := range w.pot {
+		w.pot[i] = w.g.Identity
+	}
+}
+
+// Find returns the root of i and the potential of i relative to the root.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (w *Weighted[T]) Find(i int) (root int, d T) {
+	val := w.set[i]
+	switch {
+	case val > 0:
+		return i, w.g.Identity
+	case val == 0:
+		if i == 0 {
+			panic("disjoint: node 0 points to itself")
+		}
+		fallthrough
+	default:
+		root, d := w.Find(-val)
+		if -val != root { // path compression
+			w.pot[i] = w.g.Op(w.pot[i], d)
+			w.set[i] = -root
+		}
+		return root, w.pot[i]
+	}
+}
+
+// Union records the relation that the potential of i
+// equals the potential of j composed with d, such as i = j + d.
+// Returns merged true if the groups of i and j were merged.
+// Returns consistent false if i and j are already in the same group
+// and the relation contradicts the known relation between them,
+// in which case the set is not modified.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (w *Weighted[T]) Union(i, j int, d T) (merged, consistent bool) {
+	p, di := w.Find(i)
+	q, dj := w.Find(j)
+
+	if p == q {
+		return false, w.g.Op(di, w.g.Inverse(dj)) == d
+	}
+
+	// potential of root p relative to root q
+	// follows from di + pot(p) = d + dj + pot(q).
+	pq := w.g.Op(w.g.Op(d, dj), w.g.Inverse(di))
+
+	// union by size
+	if w.set[q] >= w.set[p] {
+		w.set[q] += w.set[p]
+		w.set[p] = -q
+		w.pot[p] = pq
+	} else {
+		w.set[p] += w.set[q]
+		w.set[q] = -p
+		w.pot[q] = w.g.Inverse(pq)
+	}
+	return true, true
+}
+
+// Diff returns the potential of i relative to j, such as i - j,
+// and reports whether i and j are in the same group.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (w *Weighted[T]) Diff(i, j int) (d T, ok bool) {
+	p, di := w.Find(i)
+	q, dj := w.Find(j)
+	if p != q {
+		return d, false
+	}
+	return w.g.Op(di, w.g.Inverse(dj)), true
+}
+
+// Same reports whether nodes i and j are in the same group.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (w *Weighted[T]) Same(i, j int) bool {
+	p, _ := w.Find(i)
+	q, _ := w.Find(j)
+	return p == q
+}
+
+// Len reports the number of nodes in w.
+func (w *Weighted[T]) Len() int {
+	return len(w.set)
+}
+
+// Size reports the size of the group of i.
+// The complexity is O(α(n)), which is O(1) for all practical purposes.
+func (w *Weighted[T]) Size(i int) int {
+	p, _ := w.Find(i)
+	return w.set[p]
+}
+
+// CountGroups reports the number of groups in w.
+// The complexity is O(n).
+func (w *Weighted[T]) CountGroups() int {
+	return w.set.CountGroups()
+}
@@ -0,0 +1,83 @@
+package disjoint
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestWeightedAdditive(t *testing.T) {
+	w := NewWeighted(5)
+
+	merged, consistent := w.Union(1, 0, 3) // x1 = x0 + 3
+	require.True(t, merged && consistent)
+	merged, consistent = w.Union(2, 1, -5) // x2 = x1 - 5
+	require.True(t, merged && consistent)
+
+	d, ok := w.Diff(2, 0)
+	require.True(t, ok)
+	require.Equal(t, -2, d)
+	d, ok = w.Diff(0, 2)
+	require.True(t, ok)
+	require.Equal(t, 2, d)
+
+	merged, consistent = w.Union(2, 0, -2)
+	require.True(t, !merged && consistent)
+	merged, consistent = w.Union(2, 0, 7)
+	require.True(t, !merged && !consistent)
+
+	_, ok = w.Diff(3, 0)
+	require.True(t, !ok)
+	require.Equal(t, 3, w.Size(0))
+	require.Equal(t, 3, w.CountGroups())
+	require.Equal(t, 5, w.Len())
+	require.True(t, w.Same(0, 2))
+	require.True(t, !w.Same(0, 4))
+
+	w.Reset()
+	require.Equal(t, 5, w.CountGroups())
+}
+
+func TestWeightedRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const n = 1000
+	pot := make([]int64, n)
+	for i := range pot {
+		pot[i] = rnd.Int63n(1000) - 500
+	}
+
+	w := NewWeightedGroup(n, Additive[int64]())
+	for k := 0; k < 3000; k++ {
+		i, j := rnd.Intn(n), rnd.Intn(n)
+		_, consistent := w.Union(i, j, pot[i]-pot[j])
+		require.True(t, consistent)
+	}
+
+	for k := 0; k < 3000; k++ {
+		i, j := rnd.Intn(n), rnd.Intn(n)
+		if d, ok := w.Diff(i, j); ok {
+			require.Equal(t, pot[i]-pot[j], d)
+			_, consistent := w.Union(i, j, pot[i]-pot[j]+1)
+			require.True(t, i == j || !consistent)
+		}
+	}
+}
+
+func TestWeightedParity(t *testing.T) {
+	// an odd cycle 0-1-2-0 cannot be two-colored
+	w := NewWeightedGroup(4, Xor[uint8]())
+	_, ok := w.Union(0, 1, 1)
+	require.True(t, ok)
+	_, ok = w.Union(1, 2, 1)
+	require.True(t, ok)
+	_, ok = w.Union(2, 0, 1)
+	require.True(t, !ok)
+	_, ok = w.Union(2, 3, 1)
+	require.True(t, ok)
+
+	d, _ := w.Diff(0, 2)
+	require.Equal(t, uint8(0), d)
+	d, _ = w.Diff(0, 3)
+	require.Equal(t, uint8(1), d)
+}
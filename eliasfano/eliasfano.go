@@ -0,0 +1,194 @@
+// Package eliasfano implements Elias-Fano encoded monotone sequences.
+//
+// Elias-Fano is a compressed representation of a non-decreasing sequence
+// of n integers in the range [0, u) that uses at most 2 + log(u/n) bits per element,
+// while still supporting random access and successor queries.
+// Every element is split into low and high bits.
+// The low bits are stored verbatim in a packed array
+// and the high bits are stored in unary in a bit vector
+// that is indexed for fast select queries.
+//
+// Sets of encoded sequences can be intersected and united directly
+// without decoding them first.
+package eliasfano
+
+import (
+	"math/bits"
+
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/internal/rankselect"
+)
+
+// References:
+// Quasi-Succinct Indices
+// https://arxiv.org/abs/1206.4300
+// Partitioned Elias-Fano Indexes
+// https://www.di.unipi.it/~ottavian/files/elias_fano_sigir14.pdf
+
+// Sequence is an immutable Elias-Fano encoded sequence of non-decreasing integers.
+type Sequence struct {
+	low  *densebits.Packed
+	high *rankselect.Vector
+	n    int
+	l    int
+	last uint64
+}
+
+// New encodes values, which must be sorted in non-decreasing order.
+// Panics if values are not sorted.
+// The complexity is O(n).
+func New(values []uint64) *Sequence {
+	n := len(values)
+	s := &Sequence{n: n}
+	if n == 0 {
+		s.high = rankselect.New(nil, 0)
+		return s
+	}
+
+	s.last = values[n-1]
+	if q := s.last / uint64(n); q > 0 {
+		s.l = bits.Len64(q) - 1
+	}
+
+	if s.l > 0 {
+		s.low = densebits.NewPacked(n, s.l)
+	}
+
+	nhigh := n + int(s.last>>s.l) + 1
+	high := densebits.New(nhigh)
+	mask := uint64(1)<<s.l - 1
+	var prev uint64
+	for i, x := range values {
+		if x < prev {
+			panic("eliasfano: values are not sorted")
+		}
+		prev = x
+		high.Set(int(x>>s.l)+i, true)
+		if s.low != nil {
+			s.low.Set(i, x&mask)
+		}
+	}
+	s.high = rankselect.New(high, nhigh)
+	return s
+}
+
+// Len reports the number of elements in s.
+func (s *Sequence) Len() int {
+	return s.n
+}
+
+// Access returns the i-th element.
+// Panics if i is out of range.
+// The complexity is O(log(n)) worst case.
+func (s *Sequence) Access(i int) uint64 {
+	if i < 0 || i >= s.n {
+		panic("eliasfano: index out of range")
+	}
+	h := uint64(s.high.Select1(i) - i)
+	return h<<s.l | s.lowbits(i)
+}
+
+// NextGEQ returns the index and value of the first element
+// that is greater than or equal to x.
+// The returned index is Len() if there is no such element.
+// The complexity is O(log(n)) on average.
+func (s *Sequence) NextGEQ(x uint64) (i int, v uint64) {
+	it := s.Iter()
+	if !it.Seek(x) {
+		return s.n, 0
+	}
+	return it.Index(), it.Value()
+}
+
+// Iter returns an iterator positioned before the first element of s.
+func (s *Sequence) Iter() *Iterator {
+	return &Iterator{s: s}
+}
+
+// Decode appends the elements of s to d and returns the extended slice.
+// The complexity is O(n).
+func (s *Sequence) Decode(d []uint64) []uint64 {
+	for it := s.Iter(); it.Next(); {
+		d = append(d, it.Value())
+	}
+	return d
+}
+
+func (s *Sequence) lowbits(i int) uint64 {
+	if s.low == nil {
+		return 0
+	}
+	return s.low.Get(i)
+}
+
+// Iterator iterates over the elements of a [Sequence] in order.
+//
+//	for it := s.Iter(); it.Next(); {
+//		fmt.Println(it.Index(), it.Value())
+//	}
+type Iterator struct {
+	s   *Sequence
+	i   int
+	pos int
+	val uint64
+}
+
+// Next advances the iterator to the next element
+// and reports whether there is one.
+// The complexity is O(1) amortized.
+func (it *Iterator) Next() bool {
+	s := it.s
+	if it.i >= s.n {
+		return false
+	}
+	high := s.high.Bits()
+	w := it.pos / 64
+	x := high[w] >> (it.pos % 64) << (it.pos % 64)
+	for x == 0 {
+		w++
+		x = high[w]
+	}
+	p := w*64 + bits.TrailingZeros64(x)
+	it.val = uint64(p-it.i)<<s.l | s.lowbits(it.i)
+	it.pos = p + 1
+	it.i++
+	return true
+}
+
+// Seek advances the iterator to the first element
+// that is greater than or equal to x and reports whether there is one.
+// The iterator never moves backwards,
+// so the elements before the next element are not considered.
+// The complexity is O(log(n)) on average.
+func (it *Iterator) Seek(x uint64) bool {
+	s := it.s
+	if s.n == 0 || x > s.last {
+		it.i = s.n
+		return false
+	}
+	// jump to the first element in the bucket of x,
+	// which comes right after the h-th zero in the high bits.
+	if h := int(x >> s.l); h > 0 {
+		p := s.high.Select0(h - 1)
+		if i := p - (h - 1); i > it.i {
+			it.i = i
+			it.pos = p + 1
+		}
+	}
+	for it.Next() {
+		if it.val >= x {
+			return true
+		}
+	}
+	return false
+}
+
+// Index returns the index of the current element.
+func (it *Iterator) Index() int {
+	return it.i - 1
+}
+
+// Value returns the value of the current element.
+func (it *Iterator) Value() uint64 {
+	return it.val
+}
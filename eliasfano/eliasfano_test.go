@@ -0,0 +1,92 @@
+package eliasfano
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func randomSorted(rnd *rand.Rand, n int, max uint64) []uint64 {
+	xs := make([]uint64, n)
+	for i := range xs {
+		xs[i] = uint64(rnd.Int63n(int64(max)))
+	}
+	slices.Sort(xs)
+	return xs
+}
+
+func TestAccess(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for _, n := range []int{1, 2, 10, 1000, 10000} {
+		for _, max := range []uint64{1, 100, 1 << 20, 1 << 40} {
+			xs := randomSorted(rnd, n, max)
+			s := New(xs)
+			require.Equal(t, n, s.Len())
+			for i, x := range xs {
+				require.Equal(t, x, s.Access(i))
+			}
+			require.Equal(t, xs, s.Decode(nil))
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	s := New(nil)
+	require.Equal(t, 0, s.Len())
+	require.True(t, !s.Iter().Next())
+	i, _ := s.NextGEQ(0)
+	require.Equal(t, 0, i)
+}
+
+func TestLargeValues(t *testing.T) {
+	xs := []uint64{0, 0, 1, math.MaxUint64 / 2, math.MaxUint64}
+	s := New(xs)
+	require.Equal(t, xs, s.Decode(nil))
+	i, v := s.NextGEQ(2)
+	require.Equal(t, 3, i)
+	require.Equal(t, xs[3], v)
+}
+
+func TestUnsorted(t *testing.T) {
+	var panicked bool
+	func() {
+		defer func() { panicked = recover() != nil }()
+		New([]uint64{1, 3, 2})
+	}()
+	require.True(t, panicked)
+}
+
+func TestNextGEQ(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	xs := randomSorted(rnd, 5000, 100000)
+	s := New(xs)
+
+	for n := 0; n < 10000; n++ {
+		x := uint64(rnd.Int63n(110000))
+		j, _ := slices.BinarySearch(xs, x)
+		i, v := s.NextGEQ(x)
+		require.Equal(t, j, i)
+		if j < len(xs) {
+			require.Equal(t, xs[j], v)
+		}
+	}
+}
+
+func TestIteratorSeek(t *testing.T) {
+	xs := []uint64{3, 5, 5, 9, 100, 200, 1000}
+	s := New(xs)
+	it := s.Iter()
+	require.True(t, it.Seek(5))
+	require.Equal(t, 1, it.Index())
+	require.True(t, it.Next())
+	require.Equal(t, uint64(5), it.Value())
+	require.True(t, it.Seek(4)) // never moves backwards
+	require.Equal(t, uint64(9), it.Value())
+	require.True(t, it.Seek(150))
+	require.Equal(t, uint64(200), it.Value())
+	require.True(t, !it.Seek(1001))
+	require.True(t, !it.Next())
+}
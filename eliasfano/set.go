@@ -0,0 +1,66 @@
+package eliasfano
+
+// Intersect copies all elements that appear in both s1 and s2 to d.
+// At most min(s1.Len(), s2.Len()) elements are copied.
+// The sequences are not decoded in full;
+// instead the iterators leapfrog over each other using [Iterator.Seek].
+//
+// Intersect does not allocate memory for the result. Rather, the result is limited by len(d),
+// and the return value is the number of elements copied to d.
+// The time complexity is O(min(|s1|, |s2|) log(n)) on average.
+func Intersect(d []uint64, s1, s2 *Sequence) int {
+	k := 0
+	it1, it2 := s1.Iter(), s2.Iter()
+	ok := it1.Next() && it2.Next()
+	for ok && k < len(d) {
+		v1, v2 := it1.Value(), it2.Value()
+		switch {
+		case v1 < v2:
+			ok = it1.Seek(v2)
+		case v1 > v2:
+			ok = it2.Seek(v1)
+		default:
+			d[k] = v1
+			k++
+			ok = it1.Next() && it2.Next()
+		}
+	}
+	return k
+}
+
+// Union copies all elements in s1 and s2 in sequential order to d.
+// Elements that appear in both sequences are copied only once.
+// At most s1.Len()+s2.Len() elements are copied.
+//
+// Union does not allocate memory for the result. Rather, the result is limited by len(d),
+// and the return value is the number of elements copied to d.
+// The time complexity is O(n).
+func Union(d []uint64, s1, s2 *Sequence) int {
+	k := 0
+	it1, it2 := s1.Iter(), s2.Iter()
+	ok1, ok2 := it1.Next(), it2.Next()
+	for ; ok1 && ok2 && k < len(d); k++ {
+		v1, v2 := it1.Value(), it2.Value()
+		switch {
+		case v1 < v2:
+			d[k] = v1
+			ok1 = it1.Next()
+		case v1 > v2:
+			d[k] = v2
+			ok2 = it2.Next()
+		default:
+			d[k] = v1
+			ok1 = it1.Next()
+			ok2 = it2.Next()
+		}
+	}
+	for ; ok1 && k < len(d); k++ {
+		d[k] = it1.Value()
+		ok1 = it1.Next()
+	}
+	for ; ok2 && k < len(d); k++ {
+		d[k] = it2.Value()
+		ok2 = it2.Next()
+	}
+	return k
+}
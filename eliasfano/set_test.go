@@ -0,0 +1,40 @@
+package eliasfano
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+	"github.com/askeladdk/toolbox/xslices"
+)
+
+func TestIntersectUnion(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+
+	for _, n := range []int{0, 1, 100, 5000} {
+		xs := randomSorted(rnd, n, 20000)
+		ys := randomSorted(rnd, 2*n+1, 20000)
+		s1, s2 := New(xs), New(ys)
+
+		expected := make([]uint64, len(xs)+len(ys))
+		got := make([]uint64, len(xs)+len(ys))
+
+		k := xslices.Intersect(expected, xs, ys)
+		require.Equal(t, k, Intersect(got, s1, s2))
+		require.Equal(t, expected[:k], got[:k])
+
+		k = xslices.Union(expected, xs, ys)
+		require.Equal(t, k, Union(got, s1, s2))
+		require.Equal(t, expected[:k], got[:k])
+	}
+}
+
+func TestIntersectUnionLimit(t *testing.T) {
+	s1 := New([]uint64{1, 2, 3, 4, 5})
+	s2 := New([]uint64{2, 3, 4, 6})
+	d := make([]uint64, 2)
+	require.Equal(t, 2, Intersect(d, s1, s2))
+	require.Equal(t, []uint64{2, 3}, d)
+	require.Equal(t, 2, Union(d, s1, s2))
+	require.Equal(t, []uint64{1, 2}, d)
+}
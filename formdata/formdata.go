@@ -1,4 +1,5 @@
-// Package formdata unmarshals HTML form data [url.Values] into structs.
+// Package formdata unmarshals HTML form data [url.Values] into structs
+// and marshals structs into form data.
 //
 // # Supported data types
 //
@@ -8,24 +9,65 @@
 //   - float variants.
 //   - string.
 //   - []byte slice: Decodes base64 strings.
-//   - [encoding.TextUnmarshaler].
+//   - [encoding.TextUnmarshaler] and [encoding.TextMarshaler].
 //   - Slices of the above types.
 //   - Pointers to the above types.
+//   - Nested structs, slices of structs and maps with string keys (see below).
 //
 // # Struct tags
 //
 // The tag name is "formdata". The first argument is the name of the form key.
 // It defaults to the name of the field if not given.
 // If the name is "-", the field is skipped.
-// The second argument specifies if the field is required.
-// Required fields will give an error if they are missing in the form data.
+// The remaining arguments are options:
+//
+//   - required: Required fields will give an error if they are missing in the form data.
+//   - omitempty: [Marshal] skips the field if it has the zero value or is an empty slice.
+//   - Validators (see below).
+//
+// Other options without an argument are ignored.
+//
+// # Nested values
+//
+// Fields of nested structs and the values of map[string]T fields
+// are addressed by joining their keys, such as "address.street" or "meta.color",
+// and the elements of slices of structs by their index, such as "items[0].qty".
+// The elements are stored in order of index without gaps,
+// such that "items[0]" and "items[2]" are decoded into a slice of length 2.
+// The slice replaces any previous value of the field, like slices of scalars do.
+// [Options] selects between this dot syntax and the bracket syntax,
+// such as "address[street]" and "items[0][qty]",
+// and bounds the nesting depth and the slice indexes to prevent abuse.
+//
+// The fields of embedded structs are promoted as if they were fields
+// of the outer struct, following the same rules as encoding/json.
+// Embedded structs that are named by their tag are nested instead.
 //
 // # Data validation
 //
-// Package formdata does not validate values other than converting types.
-// Types can validate themselves by implementing the [encoding.TextUnmarshaler] interface.
+// Options of the form name=argument, or just name, are validators
+// that check the value of a field after it has been decoded.
+// Fields that are missing from the form data are not validated.
+// The builtin validators are:
+//
+//   - min=x, max=x: Numbers must be at least or at most x.
+//   - minlen=n, maxlen=n: Strings must have at least or at most n characters,
+//     and slices and maps at least or at most n elements.
+//   - pattern=re: Strings must match the regular expression re.
+//     It must be the last option because re extends to the end of the tag.
+//   - oneof=a b c: Values must be equal to one of the space separated values.
+//   - email: Strings must be an email address such as "gopher@example.com".
+//   - url: Strings must be an absolute URL with a scheme and a host.
+//
+// Validators other than minlen and maxlen check every element of slices.
+// Custom validators can be registered by name with [RegisterValidator].
+// Types can also validate themselves by implementing the [encoding.TextUnmarshaler] interface.
+// The options of a struct type are checked when it is first marshaled or unmarshaled,
+// which panics if an option with an argument names an unknown validator,
+// or if a validator does not support its argument or the type of the field.
 //
 // Any errors that occur during unmarshaling are accumulated and returned as an instance of [Errors].
+// Every [Error] has a machine-readable code that identifies the failed validator.
 //
 // # Default values
 //
@@ -36,7 +78,7 @@
 //	type Form struct {
 //		Name     string `formdata:"name,required"`
 //		FavColor string `formdata:"favcolor"`
-//		Age      int    `formdata:"age"`
+//		Age      int    `formdata:"age,min=0,max=150"`
 //		Address  string `formdata:"-"`
 //	}
 //	values := url.Values{}
@@ -45,6 +87,12 @@
 //	var form Form
 //	form.FavColor = "blue"
 //	formdata.Unmarshal(values, &form)
+//
+// # Marshaling
+//
+// [Marshal] is the reverse of [Unmarshal] and encodes the same data types
+// such that the result unmarshals into an equal struct.
+// Bools are encoded as "true" or "false" and nil pointers are skipped.
 package formdata
 
 import (
@@ -166,41 +214,199 @@ func decodeValue(v reflect.Value, vtype reflect.Type, vals []string) error {
 	return decodeScalar(v, vtype, vals[0])
 }
 
-func decodeField(data url.Values, v reflect.Value, field reflect.StructField) (Error, bool) {
-	if field.Anonymous || !field.IsExported() {
-		return Error{}, true
-	}
-	tag := field.Tag.Get("formdata")
-	name, rest, _ := strings.Cut(tag, ",")
-	if name == "-" { // skip
-		return Error{}, true
-	}
+// parseTag returns the form key and the comma separated options of field.
+func parseTag(field reflect.StructField) (name, opts string) {
+	name, opts, _ = strings.Cut(field.Tag.Get("formdata"), ",")
 	if name == "" { // default name
 		name = field.Name
 	}
-	vals, exists := data[name]
+	return name, opts
+}
+
+// splitOptions splits the comma separated options of a tag.
+// The pattern option extends to the end of the tag,
+// so that its regular expression may contain commas.
+func splitOptions(opts string) []string {
+	var d []string
+	for opts != "" {
+		if strings.HasPrefix(opts, "pattern=") {
+			return append(d, opts)
+		}
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		d = append(d, opt)
+	}
+	return d
+}
+
+// hasOption reports whether option is one of the comma separated opts.
+func hasOption(opts, option string) bool {
+	return slices.Contains(splitOptions(opts), option)
+}
+
+// decoder holds the state of unmarshaling nested values.
+type decoder struct {
+	data url.Values
+	keys []string // sorted keys of data
+	opts *Options
+	errs Errors
+}
+
+func (d *decoder) fail(key string, vals []string, code string, err error) {
+	d.errs = append(d.errs, Error{
+		Field: key,
+		Value: vals,
+		Code:  code,
+		Err:   err,
+	})
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex
+// but allocates nil pointers to embedded structs.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func (d *decoder) decodeStruct(v reflect.Value, key string, depth int) {
+	for _, f := range structFields(v.Type()) {
+		d.decodeField(v, f, d.opts.join(key, f.name), depth)
+	}
+}
+
+func (d *decoder) decodeField(v reflect.Value, f field, key string, depth int) {
+	if isNested(f.typ) {
+		if !d.opts.hasChildren(d.keys, key) { // missing value
+			if hasOption(f.opts, "required") {
+				d.fail(key, nil, CodeRequired, errRequiredFieldMissing)
+			}
+			return
+		}
+		fv := fieldByIndex(v, f.index)
+		n := len(d.errs)
+		d.decodeNested(fv, key, depth+1)
+		if len(d.errs) == n {
+			d.validate(fv, key, nil, f.opts)
+		}
+		return
+	}
+	vals, exists := d.data[key]
 	if !exists { // missing value
-		if rest == "required" {
-			return Error{
-				Field: name,
-				Err:   errRequiredFieldMissing,
-			}, false
+		if hasOption(f.opts, "required") {
+			d.fail(key, nil, CodeRequired, errRequiredFieldMissing)
 		}
-		return Error{}, true
+		return
 	}
-	fv := v.FieldByIndex(field.Index)
+	fv := fieldByIndex(v, f.index)
 	if len(vals) == 0 { // empty value
-		fv.Set(reflect.Zero(field.Type))
-		return Error{}, true
+		fv.Set(reflect.Zero(f.typ))
+		return
 	}
-	if err := decodeValue(fv, field.Type, vals); err != nil {
-		return Error{
-			Field: name,
-			Value: vals,
-			Err:   err,
-		}, false
+	if err := decodeValue(fv, f.typ, vals); err != nil {
+		d.fail(key, vals, CodeInvalid, err)
+		return
+	}
+	d.validate(fv, key, vals, f.opts)
+}
+
+// decodeKey decodes the value of key into v
+// and reports whether key exists.
+func (d *decoder) decodeKey(v reflect.Value, key string, depth int) bool {
+	if isNested(v.Type()) {
+		if !d.opts.hasChildren(d.keys, key) {
+			return false
+		}
+		d.decodeNested(v, key, depth+1)
+		return true
+	}
+	vals, exists := d.data[key]
+	if exists && len(vals) != 0 {
+		if err := decodeValue(v, v.Type(), vals); err != nil {
+			d.fail(key, vals, CodeInvalid, err)
+		}
+	}
+	return exists
+}
+
+// decodeNested decodes the keys nested below key into v,
+// which is a struct, map or slice or a pointer to one.
+func (d *decoder) decodeNested(v reflect.Value, key string, depth int) {
+	if depth > d.opts.maxDepth() {
+		d.fail(key, nil, CodeInvalid, errMaxDepth)
+		return
+	}
+
+	// deref pointer
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		d.decodeStruct(v, key, depth)
+	case reflect.Slice:
+		d.decodeIndexed(v, key, depth)
+	case reflect.Map:
+		d.decodeMap(v, key, depth)
+	}
+}
+
+func (d *decoder) decodeIndexed(v reflect.Value, key string, depth int) {
+	segs := d.opts.children(d.keys, key, true)
+	indexes := make([]int, 0, len(segs))
+	for _, seg := range segs {
+		i, err := strconv.Atoi(seg)
+		switch {
+		case err != nil || i < 0 || strconv.Itoa(i) != seg:
+			d.fail(key+"["+seg+"]", nil, CodeInvalid, errInvalidIndex)
+			return
+		case i >= d.opts.maxIndex():
+			d.fail(key+"["+seg+"]", nil, CodeInvalid, errMaxIndex)
+			return
+		}
+		if d.opts.hasChildren(d.keys, d.opts.index(key, i)) {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return
+	}
+
+	// compact the present indexes into a new dense slice
+	slices.Sort(indexes)
+	v.Set(reflect.MakeSlice(v.Type(), len(indexes), len(indexes)))
+	for j, i := range indexes {
+		d.decodeKey(v.Index(j), d.opts.index(key, i), depth)
+	}
+}
+
+func (d *decoder) decodeMap(v reflect.Value, key string, depth int) {
+	vtype := v.Type()
+	if vtype.Key().Kind() != reflect.String {
+		d.fail(key, nil, CodeInvalid, fmt.Errorf("cannot unmarshal into unsupported type %q", vtype))
+		return
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(vtype))
+	}
+	elem := reflect.New(vtype.Elem()).Elem()
+	for _, seg := range d.opts.children(d.keys, key, false) {
+		elem.SetZero()
+		if d.decodeKey(elem, d.opts.join(key, seg), depth) {
+			v.SetMapIndex(reflect.ValueOf(seg).Convert(vtype.Key()), elem)
+		}
 	}
-	return Error{}, true
 }
 
 func structTypeOf(t reflect.Type) reflect.Type {
@@ -217,34 +423,52 @@ func structTypeOf(t reflect.Type) reflect.Type {
 	return t
 }
 
-// Unmarshal decodes a map into a struct.
+// Unmarshal decodes a map into a struct using the default [Options].
 // Parameter v must be a pointer to a struct.
 // Returns [Errors] if there are any unmarshaling errors.
 func Unmarshal(data map[string][]string, v any) error {
+	return Options{}.Unmarshal(data, v)
+}
+
+// Unmarshal decodes a map into a struct.
+// Parameter v must be a pointer to a struct.
+// Returns [Errors] if there are any unmarshaling errors.
+func (o Options) Unmarshal(data map[string][]string, v any) error {
 	structType := structTypeOf(reflect.TypeOf(v))
 	if structType == nil {
 		return errNotAPointerToStruct
 	}
-	var errors Errors
-	structValue := reflect.ValueOf(v).Elem()
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-		if err, ok := decodeField(data, structValue, field); !ok {
-			errors = append(errors, err)
-		}
+	d := decoder{
+		data: data,
+		keys: sortedKeys(data),
+		opts: &o,
 	}
-	if len(errors) > 0 {
-		return errors
+	d.decodeStruct(reflect.ValueOf(v).Elem(), "", 0)
+	if len(d.errs) > 0 {
+		return d.errs
 	}
 	return nil
 }
 
+// Machine-readable codes of errors that are not caused by a validator.
+const (
+	// CodeRequired is the code of required fields that are missing.
+	CodeRequired = "required"
+
+	// CodeInvalid is the code of values that cannot be converted
+	// and of structural errors such as exceeding the limits of [Options].
+	CodeInvalid = "invalid"
+)
+
 // Error holds the information of an unmarshaling error.
 type Error struct {
 	// The field where the error occurred.
 	Field string
 	// The input value that was passed.
 	Value []string
+	// The machine-readable error code, which is either
+	// CodeRequired, CodeInvalid or the name of the failed validator.
+	Code string
 	// The actual error.
 	Err error
 }
@@ -0,0 +1,217 @@
+package formdata
+
+import (
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errNotAStruct     = errors.New("formdata: expected struct or pointer to struct")
+)
+
+func encodeScalar(v reflect.Value) (string, error) {
+	vtype := v.Type()
+	// special case for TextMarshaler
+	if vtype.Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if reflect.PointerTo(vtype).Implements(textMarshalerType) {
+		if !v.CanAddr() {
+			tmp := reflect.New(vtype)
+			tmp.Elem().Set(v)
+			v = tmp.Elem()
+		}
+		text, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch vtype.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, vtype.Bits()), nil
+	case reflect.String:
+		return v.String(), nil
+	}
+	// special case for []byte slice
+	if vtype.ConvertibleTo(byteSliceType) {
+		return base64.StdEncoding.EncodeToString(v.Convert(byteSliceType).Bytes()), nil
+	}
+	return "", fmt.Errorf("cannot marshal unsupported type %q", vtype)
+}
+
+func encodeValue(v reflect.Value) ([]string, error) {
+	// deref pointer
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	// encode slices
+	if v.Kind() == reflect.Slice {
+		// special case for []byte slice
+		if v.Type().ConvertibleTo(byteSliceType) {
+			return []string{base64.StdEncoding.EncodeToString(v.Convert(byteSliceType).Bytes())}, nil
+		}
+		if v.Len() == 0 {
+			return nil, nil
+		}
+		vals := make([]string, v.Len())
+		for i := range vals {
+			data, err := encodeScalar(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = data
+		}
+		return vals, nil
+	}
+	// encode scalar
+	data, err := encodeScalar(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{data}, nil
+}
+
+// isNil reports whether v is a nil pointer or points to a nil pointer.
+func isNil(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return false
+}
+
+// isEmpty reports whether v is the zero value or an empty slice or map.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// encoder holds the state of marshaling nested values.
+type encoder struct {
+	data url.Values
+	opts *Options
+	errs Errors
+}
+
+func (e *encoder) fail(key string, err error) {
+	e.errs = append(e.errs, Error{
+		Field: key,
+		Code:  CodeInvalid,
+		Err:   err,
+	})
+}
+
+func (e *encoder) encodeStruct(v reflect.Value, key string, depth int) {
+	for _, f := range structFields(v.Type()) {
+		fv, err := v.FieldByIndexErr(f.index)
+		if err != nil { // nil pointer to embedded struct
+			continue
+		}
+		if hasOption(f.opts, "omitempty") && isEmpty(fv) {
+			continue
+		}
+		e.encodeKey(fv, e.opts.join(key, f.name), depth)
+	}
+}
+
+func (e *encoder) encodeKey(v reflect.Value, key string, depth int) {
+	if isNil(v) {
+		return
+	}
+	if !isNested(v.Type()) {
+		vals, err := encodeValue(v)
+		if err != nil {
+			e.fail(key, err)
+			return
+		}
+		e.data[key] = vals
+		return
+	}
+
+	if depth++; depth > e.opts.maxDepth() {
+		e.fail(key, errMaxDepth)
+		return
+	}
+
+	// deref pointer
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		e.encodeStruct(v, key, depth)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			e.encodeKey(v.Index(i), e.opts.index(key, i), depth)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			e.fail(key, fmt.Errorf("cannot marshal unsupported type %q", v.Type()))
+			return
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		for _, k := range keys {
+			name := e.opts.join(key, k.String())
+			if !e.opts.validKey(k.String()) {
+				e.fail(name, errInvalidKey)
+				continue
+			}
+			e.encodeKey(v.MapIndex(k), name, depth)
+		}
+	}
+}
+
+// Marshal encodes a struct into form data using the default [Options].
+// Parameter v must be a struct or a pointer to a struct.
+// Fields are encoded symmetrically with [Unmarshal]
+// using the same struct tags.
+// Returns [Errors] if there are any marshaling errors.
+func Marshal(v any) (url.Values, error) {
+	return Options{}.Marshal(v)
+}
+
+// Marshal encodes a struct into form data.
+// Parameter v must be a struct or a pointer to a struct.
+// Fields are encoded symmetrically with [Options.Unmarshal]
+// using the same struct tags.
+// Returns [Errors] if there are any marshaling errors.
+func (o Options) Marshal(v any) (url.Values, error) {
+	structValue := reflect.ValueOf(v)
+	if structValue.Kind() == reflect.Pointer && !structValue.IsNil() {
+		structValue = structValue.Elem()
+	}
+	if structValue.Kind() != reflect.Struct {
+		return nil, errNotAStruct
+	}
+	e := encoder{
+		data: url.Values{},
+		opts: &o,
+	}
+	e.encodeStruct(structValue, "", 0)
+	if len(e.errs) > 0 {
+		return e.data, e.errs
+	}
+	return e.data, nil
+}
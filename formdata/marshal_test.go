@@ -0,0 +1,138 @@
+package formdata
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+type textMarshaler struct {
+	Value int
+}
+
+func (t textMarshaler) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(t.Value), 10), nil
+}
+
+func (t *textMarshaler) UnmarshalText(x []byte) (err error) {
+	t.Value, err = strconv.Atoi(string(x))
+	return err
+}
+
+type pointerMarshaler struct {
+	Value string
+}
+
+func (t *pointerMarshaler) MarshalText() ([]byte, error) {
+	return []byte("<" + t.Value + ">"), nil
+}
+
+func TestMarshal(t *testing.T) {
+	x := 7
+	testform := struct {
+		Name    string  `formdata:"name,required"`
+		Age     int     `formdata:"age"`
+		Ratio   float32 `formdata:"ratio"`
+		Count   uint8   `formdata:"count"`
+		Checked bool    `formdata:"checked"`
+		Skip    int     `formdata:"-"`
+		Default string
+		Ptr     *int            `formdata:"ptr"`
+		NilPtr  *int            `formdata:"nilptr"`
+		Bytes   []byte          `formdata:"bytes"`
+		Ints    []int           `formdata:"ints"`
+		Empty   []int           `formdata:"empty"`
+		Text    textMarshaler   `formdata:"text"`
+		Texts   []textMarshaler `formdata:"texts"`
+		skip    int
+	}{
+		Name:    "Gopher",
+		Age:     -42,
+		Ratio:   0.1,
+		Count:   255,
+		Checked: true,
+		Skip:    1,
+		Default: "x",
+		Ptr:     &x,
+		Bytes:   []byte("hello"),
+		Ints:    []int{1, 2, 3},
+		Text:    textMarshaler{1337},
+		Texts:   []textMarshaler{{1}, {2}},
+		skip:    1,
+	}
+
+	data, err := Marshal(&testform)
+	require.NoError(t, err)
+	require.Equal(t, url.Values{
+		"name":    {"Gopher"},
+		"age":     {"-42"},
+		"ratio":   {"0.1"},
+		"count":   {"255"},
+		"checked": {"true"},
+		"Default": {"x"},
+		"ptr":     {"7"},
+		"bytes":   {"aGVsbG8="},
+		"ints":    {"1", "2", "3"},
+		"empty":   nil,
+		"text":    {"1337"},
+		"texts":   {"1", "2"},
+	}, data)
+
+	// round trip
+	decoded := testform
+	decoded.Ptr = nil
+	decoded.Bytes = nil
+	decoded.Ints = nil
+	decoded.Empty = []int{1}
+	decoded.Text = textMarshaler{}
+	decoded.Texts = nil
+	decoded.Skip = 0
+	require.NoError(t, Unmarshal(data, &decoded))
+	decoded.Skip = 1
+	require.Equal(t, testform, decoded)
+}
+
+func TestMarshalOmitEmpty(t *testing.T) {
+	var testform struct {
+		A int       `formdata:"a,omitempty"`
+		B string    `formdata:"b,omitempty"`
+		C []string  `formdata:"c,omitempty"`
+		D *int      `formdata:"d,omitempty"`
+		E bool      `formdata:"e,omitempty"`
+		F int       `formdata:"f,required,omitempty"`
+		G [0]string `formdata:"g"`
+	}
+	testform.C = []string{}
+	data, err := Marshal(testform)
+	require.Equal(t, `error in field "g": cannot marshal unsupported type "[0]string"`, err.Error())
+	require.Equal(t, url.Values{}, data)
+
+	testform.F = 1
+	data, _ = Marshal(testform)
+	require.Equal(t, url.Values{"f": {"1"}}, data)
+}
+
+func TestMarshalPointerReceiver(t *testing.T) {
+	testform := struct {
+		X pointerMarshaler `formdata:"x"`
+	}{pointerMarshaler{"a"}}
+
+	data, err := Marshal(&testform)
+	require.NoError(t, err)
+	require.Equal(t, "<a>", data.Get("x"))
+
+	// not addressable
+	data, err = Marshal(testform)
+	require.NoError(t, err)
+	require.Equal(t, "<a>", data.Get("x"))
+}
+
+func TestMarshalErrorNotAStruct(t *testing.T) {
+	for _, v := range []any{nil, 42, (*struct{})(nil), &[]string{}} {
+		_, err := Marshal(v)
+		require.True(t, errors.Is(err, errNotAStruct))
+	}
+}
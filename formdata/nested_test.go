@@ -0,0 +1,280 @@
+package formdata
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+type address struct {
+	Street string `formdata:"street,required"`
+	City   string `formdata:"city"`
+}
+
+type item struct {
+	Name string `formdata:"name"`
+	Qty  int    `formdata:"qty"`
+}
+
+type nestedForm struct {
+	Name    string            `formdata:"name"`
+	Address address           `formdata:"address"`
+	Billing *address          `formdata:"billing"`
+	Items   []item            `formdata:"items"`
+	Meta    map[string]string `formdata:"meta"`
+	Tags    map[string][]int  `formdata:"tags"`
+	Extra   map[string]item   `formdata:"extra"`
+}
+
+func TestNestedDot(t *testing.T) {
+	data := url.Values{
+		"name":            {"Gopher"},
+		"address.street":  {"Main"},
+		"address.city":    {"Go City"},
+		"items[0].name":   {"apple"},
+		"items[0].qty":    {"3"},
+		"items[2].qty":    {"5"},
+		"meta.color":      {"blue"},
+		"meta.size":       {"L"},
+		"tags.a":          {"1", "2"},
+		"extra.x.qty":     {"7"},
+		"items[1]x":       {"ignored"},
+		"itemsx[0].qty":   {"ignored"},
+		"meta":            {"ignored"},
+		"address.unknown": {"ignored"},
+	}
+
+	var form nestedForm
+	require.NoError(t, Unmarshal(data, &form))
+	require.Equal(t, nestedForm{
+		Name:    "Gopher",
+		Address: address{"Main", "Go City"},
+		Items:   []item{{"apple", 3}, {"", 5}},
+		Meta:    map[string]string{"color": "blue", "size": "L"},
+		Tags:    map[string][]int{"a": {1, 2}},
+		Extra:   map[string]item{"x": {Qty: 7}},
+	}, form)
+
+	encoded, err := Marshal(&form)
+	require.NoError(t, err)
+	var decoded nestedForm
+	require.NoError(t, Unmarshal(encoded, &decoded))
+	require.Equal(t, form, decoded)
+	require.Equal(t, "5", encoded.Get("items[1].qty"))
+	require.True(t, !encoded.Has("items[2].qty"))
+}
+
+func TestNestedBracket(t *testing.T) {
+	opts := Options{Syntax: Bracket}
+	data := url.Values{
+		"address[street]": {"Main"},
+		"billing[street]": {"Side"},
+		"items[1][name]":  {"pear"},
+		"meta[color]":     {"red"},
+		"address.city":    {"ignored"},
+	}
+
+	var form nestedForm
+	require.NoError(t, opts.Unmarshal(data, &form))
+	require.Equal(t, nestedForm{
+		Address: address{Street: "Main"},
+		Billing: &address{Street: "Side"},
+		Items:   []item{{Name: "pear"}},
+		Meta:    map[string]string{"color": "red"},
+	}, form)
+
+	encoded, err := opts.Marshal(form)
+	require.NoError(t, err)
+	require.Equal(t, "Side", encoded.Get("billing[street]"))
+	require.Equal(t, "pear", encoded.Get("items[0][name]"))
+	require.Equal(t, "red", encoded.Get("meta[color]"))
+
+	var decoded nestedForm
+	require.NoError(t, opts.Unmarshal(encoded, &decoded))
+	require.Equal(t, form, decoded)
+}
+
+func TestNestedRequired(t *testing.T) {
+	var form struct {
+		Address address  `formdata:"address,required"`
+		Billing *address `formdata:"billing"`
+	}
+	data := url.Values{"billing.city": {"x"}}
+	err := Unmarshal(data, &form)
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Equal(t, 2, len(errs))
+	require.Equal(t, "address", errs[0].Field)
+	require.Equal(t, "billing.street", errs[1].Field)
+	require.Equal(t, "x", form.Billing.City)
+}
+
+type Base struct {
+	ID   int    `formdata:"id"`
+	Name string `formdata:"name"`
+}
+
+type timestamps struct {
+	Created string `formdata:"created"`
+}
+
+type Other struct {
+	Name string `formdata:"name"`
+	Note string `formdata:"note"`
+}
+
+func TestNestedSparseIndexes(t *testing.T) {
+	var form struct {
+		Addresses []address `formdata:"addresses"`
+	}
+	data := url.Values{
+		"addresses[3].street":  {"Main"},
+		"addresses[10].street": {"Side"},
+		"addresses[7].city":    {"Go City"},
+	}
+	err := Unmarshal(data, &form)
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Equal(t, 1, len(errs))
+	require.Equal(t, "addresses[7].street", errs[0].Field)
+	require.Equal(t, CodeRequired, errs[0].Code)
+	require.Equal(t, []address{{"Main", ""}, {"", "Go City"}, {"Side", ""}}, form.Addresses)
+}
+
+func TestNestedReplacesDefaults(t *testing.T) {
+	form := nestedForm{
+		Items: []item{{"a", 1}, {"b", 2}, {"c", 3}},
+	}
+	require.NoError(t, Unmarshal(url.Values{"items[0].qty": {"9"}}, &form))
+	require.Equal(t, []item{{Qty: 9}}, form.Items)
+
+	encoded, err := Marshal(nestedForm{Items: []item{{"x", 1}}})
+	require.NoError(t, err)
+	require.NoError(t, Unmarshal(encoded, &form))
+	require.Equal(t, []item{{"x", 1}}, form.Items)
+}
+
+func TestEmbedded(t *testing.T) {
+	type form struct {
+		Base
+		*Other
+		timestamps
+		Name  string `formdata:"name"`
+		Inner Base   `formdata:"inner"`
+	}
+
+	data := url.Values{
+		"id":         {"1"},
+		"name":       {"outer"},
+		"note":       {"n"},
+		"created":    {"today"},
+		"inner.name": {"inner"},
+	}
+
+	var f form
+	require.NoError(t, Unmarshal(data, &f))
+	require.Equal(t, 1, f.ID)
+	require.Equal(t, "outer", f.Name)
+	require.Equal(t, "", f.Base.Name)
+	require.Equal(t, "n", f.Other.Note)
+	require.Equal(t, "", f.Other.Name)
+	require.Equal(t, "today", f.Created)
+	require.Equal(t, "inner", f.Inner.Name)
+
+	encoded, err := Marshal(f)
+	require.NoError(t, err)
+	require.Equal(t, url.Values{
+		"id":         {"1"},
+		"name":       {"outer"},
+		"note":       {"n"},
+		"created":    {"today"},
+		"inner.id":   {"0"},
+		"inner.name": {"inner"},
+	}, encoded)
+
+	// nil embedded pointers are skipped
+	f.Other = nil
+	encoded, _ = Marshal(f)
+	_, ok := encoded["note"]
+	require.True(t, !ok)
+
+	// ambiguous fields at the same depth hide each other
+	var ambiguous struct {
+		Base
+		Other
+	}
+	require.NoError(t, Unmarshal(url.Values{"name": {"x"}, "id": {"2"}}, &ambiguous))
+	require.Equal(t, "", ambiguous.Base.Name)
+	require.Equal(t, "", ambiguous.Other.Name)
+	require.Equal(t, 2, ambiguous.ID)
+}
+
+type node struct {
+	Value int   `formdata:"value"`
+	Next  *node `formdata:"next"`
+}
+
+func TestNestedLimits(t *testing.T) {
+	opts := Options{MaxDepth: 2, MaxIndex: 10}
+
+	var n node
+	err := opts.Unmarshal(url.Values{"next.next.value": {"1"}}, &n)
+	require.NoError(t, err)
+	require.Equal(t, 1, n.Next.Next.Value)
+
+	err = opts.Unmarshal(url.Values{"next.next.next.value": {"1"}}, &n)
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Equal(t, "next.next.next", errs[0].Field)
+	require.True(t, errors.Is(errs[0].Err, errMaxDepth))
+
+	// cycles are cut off by the depth limit
+	cycle := &node{Value: 1}
+	cycle.Next = cycle
+	_, err = opts.Marshal(cycle)
+	require.True(t, errors.As(err, &errs))
+	require.True(t, errors.Is(errs[0].Err, errMaxDepth))
+
+	var form nestedForm
+	err = opts.Unmarshal(url.Values{"items[10].qty": {"1"}}, &form)
+	require.True(t, errors.As(err, &errs))
+	require.Equal(t, "items[10]", errs[0].Field)
+	require.True(t, errors.Is(errs[0].Err, errMaxIndex))
+	require.Equal(t, 0, len(form.Items))
+
+	for _, key := range []string{"items[-1].qty", "items[x].qty", "items[1e3].qty", "items[01].qty", "items[+2].qty"} {
+		err = opts.Unmarshal(url.Values{key: {"1"}}, &form)
+		require.True(t, errors.As(err, &errs))
+		require.True(t, errors.Is(errs[0].Err, errInvalidIndex))
+	}
+}
+
+func TestNestedMapErrors(t *testing.T) {
+	form := struct {
+		Meta map[string]string `formdata:"meta"`
+		Ints map[int]string    `formdata:"ints"`
+	}{
+		Meta: map[string]string{"a.b": "x", "c": "y"},
+		Ints: map[int]string{1: "x"},
+	}
+
+	encoded, err := Marshal(form)
+	var errs Errors
+	require.True(t, errors.As(err, &errs))
+	require.Equal(t, 2, len(errs))
+	require.Equal(t, "meta.a.b", errs[0].Field)
+	require.True(t, errors.Is(errs[0].Err, errInvalidKey))
+	require.Equal(t, `error in field "ints": cannot marshal unsupported type "map[int]string"`, errs[1].Error())
+	require.Equal(t, "y", encoded.Get("meta.c"))
+
+	encoded, err = Options{Syntax: Bracket}.Marshal(struct {
+		Meta map[string]string `formdata:"meta"`
+	}{form.Meta})
+	require.NoError(t, err)
+	require.Equal(t, "x", encoded.Get("meta[a.b]"))
+
+	err = Unmarshal(url.Values{"ints.1": {"x"}}, &form)
+	require.Equal(t, `error in field "ints": cannot unmarshal into unsupported type "map[int]string"`, err.Error())
+}
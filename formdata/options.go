@@ -0,0 +1,279 @@
+package formdata
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	errMaxDepth     = errors.New("exceeds maximum nesting depth")
+	errMaxIndex     = errors.New("index exceeds maximum")
+	errInvalidIndex = errors.New("invalid index")
+	errInvalidKey   = errors.New("map key contains a separator")
+)
+
+// Syntax determines how the keys of nested values are written.
+type Syntax int
+
+const (
+	// Dot writes struct fields and map keys as "a.b"
+	// and slice indexes as "a[0]", such as "items[0].qty".
+	Dot Syntax = iota
+
+	// Bracket writes struct fields and map keys as "a[b]"
+	// and slice indexes as "a[0]", such as "items[0][qty]".
+	Bracket
+)
+
+const (
+	// DefaultMaxDepth is the default maximum nesting depth.
+	DefaultMaxDepth = 8
+
+	// DefaultMaxIndex is the default upper bound of slice indexes.
+	DefaultMaxIndex = 1000
+)
+
+// Options configures the key syntax and limits of nested values.
+// The zero value uses the Dot syntax and the default limits.
+type Options struct {
+	// Syntax is the syntax of nested keys.
+	Syntax Syntax
+
+	// MaxDepth is the maximum nesting depth of structs, slices and maps
+	// below the top-level struct.
+	// Defaults to DefaultMaxDepth if zero.
+	MaxDepth int
+
+	// MaxIndex is the upper bound of slice indexes when unmarshaling,
+	// such that every index must be less than MaxIndex.
+	// It limits the size of slices that are allocated for indexed keys.
+	// Defaults to DefaultMaxIndex if zero.
+	MaxIndex int
+}
+
+func (o *Options) maxDepth() int {
+	if o.MaxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return o.MaxDepth
+}
+
+func (o *Options) maxIndex() int {
+	if o.MaxIndex <= 0 {
+		return DefaultMaxIndex
+	}
+	return o.MaxIndex
+}
+
+// join returns the key of the child name of key.
+func (o *Options) join(key, name string) string {
+	switch {
+	case key == "":
+		return name
+	case o.Syntax == Bracket:
+		return key + "[" + name + "]"
+	default:
+		return key + "." + name
+	}
+}
+
+// index returns the key of the i-th element of key.
+func (o *Options) index(key string, i int) string {
+	return key + "[" + strconv.Itoa(i) + "]"
+}
+
+// validKey reports whether name can be used as a map key
+// without being mistaken for a separator.
+func (o *Options) validKey(name string) bool {
+	if o.Syntax == Bracket {
+		return !strings.ContainsAny(name, "[]")
+	}
+	return !strings.ContainsAny(name, ".[]")
+}
+
+// segment returns the first segment of rest, which is the remainder of a key
+// following the key of its parent, and reports whether it is a segment
+// of the requested kind.
+func (o *Options) segment(rest string, index bool) (string, bool) {
+	if o.Syntax == Dot && !index {
+		if rest, ok := strings.CutPrefix(rest, "."); ok {
+			if i := strings.IndexAny(rest, ".["); i >= 0 {
+				rest = rest[:i]
+			}
+			return rest, rest != ""
+		}
+		return "", false
+	}
+	if rest, ok := strings.CutPrefix(rest, "["); ok {
+		seg, _, ok := strings.Cut(rest, "]")
+		return seg, ok && seg != ""
+	}
+	return "", false
+}
+
+// hasChildren reports whether data contains any key nested below key.
+// keys are the sorted keys of data.
+func (o *Options) hasChildren(keys []string, key string) bool {
+	for i := sort.SearchStrings(keys, key); i < len(keys) && strings.HasPrefix(keys[i], key); i++ {
+		rest := keys[i][len(key):]
+		if _, ok := o.segment(rest, false); ok {
+			return true
+		}
+		if _, ok := o.segment(rest, true); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// children returns the distinct first segments of all keys nested below key
+// in sorted order. keys are the sorted keys of data.
+func (o *Options) children(keys []string, key string, index bool) []string {
+	var segs []string
+	for i := sort.SearchStrings(keys, key); i < len(keys) && strings.HasPrefix(keys[i], key); i++ {
+		if seg, ok := o.segment(keys[i][len(key):], index); ok {
+			segs = append(segs, seg)
+		}
+	}
+	slices.Sort(segs)
+	return slices.Compact(segs)
+}
+
+// sortedKeys returns the keys of data in sorted order.
+func sortedKeys(data url.Values) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// field describes a struct field that is marshaled or unmarshaled.
+type field struct {
+	name  string
+	opts  string
+	index []int
+	typ   reflect.Type
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// structFields returns the fields of struct type t and caches them.
+// It panics the first time if the options of a field are invalid.
+func structFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	fields := typeFields(t)
+	for _, f := range fields {
+		checkOptions(f)
+	}
+	cached, _ := fieldCache.LoadOrStore(t, fields)
+	return cached.([]field)
+}
+
+// typeFields returns the fields of struct type t, including the fields
+// that are promoted from embedded structs, in order of declaration.
+// Like encoding/json, fields at a shallower depth hide fields with
+// the same name at a deeper depth, and fields with the same name at
+// the same depth hide each other.
+// Embedded structs that are given a name by their tag are not promoted.
+func typeFields(t reflect.Type) []field {
+	var fields []field
+	taken := map[string]bool{}
+	visited := map[reflect.Type]bool{}
+
+	current := []field{{typ: t}}
+	for len(current) > 0 {
+		var next, level []field
+		count := map[string]int{}
+
+		for _, f := range current {
+			st := f.typ
+			if st.Kind() == reflect.Pointer {
+				st = st.Elem()
+			}
+			if visited[st] {
+				continue
+			}
+			visited[st] = true
+
+			for i := 0; i < st.NumField(); i++ {
+				sf := st.Field(i)
+				index := append(slices.Clip(f.index), i)
+				tag := sf.Tag.Get("formdata")
+
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && (ft.Kind() != reflect.Struct || sf.Type.Kind() == reflect.Pointer) {
+						continue // cannot be set through reflection
+					}
+					name, _, _ := strings.Cut(tag, ",")
+					if name == "" && ft.Kind() == reflect.Struct && !isText(ft) {
+						next = append(next, field{index: index, typ: sf.Type})
+						continue
+					}
+				}
+				if !sf.IsExported() {
+					continue
+				}
+
+				name, opts := parseTag(sf)
+				if name == "-" { // skip
+					continue
+				}
+				level = append(level, field{name, opts, index, sf.Type})
+				count[name]++
+			}
+		}
+
+		for _, f := range level {
+			if !taken[f.name] && count[f.name] == 1 {
+				fields = append(fields, f)
+			}
+		}
+		for name := range count {
+			taken[name] = true
+		}
+
+		current = next
+	}
+
+	slices.SortFunc(fields, func(a, b field) int {
+		return slices.Compare(a.index, b.index)
+	})
+	return fields
+}
+
+// isText reports whether values of type t are encoded as text
+// by implementing encoding.TextUnmarshaler or encoding.TextMarshaler.
+func isText(t reflect.Type) bool {
+	return isTextUnmarshaler(t) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// isNested reports whether values of type t are encoded as nested keys.
+func isNested(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isText(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return true
+	case reflect.Slice:
+		return isNested(t.Elem())
+	}
+	return false
+}
@@ -0,0 +1,366 @@
+package formdata
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Validator checks a decoded value against the argument of its option,
+// which is empty if the option has no argument.
+// The value is the decoded field with pointers dereferenced.
+// Validator returns an error if the value is invalid.
+type Validator func(v any, arg string) error
+
+// validator is a builtin validator.
+type validator func(v reflect.Value, arg string) error
+
+var builtins = map[string]validator{
+	"min":     validateMin,
+	"max":     validateMax,
+	"minlen":  validateMinLen,
+	"maxlen":  validateMaxLen,
+	"pattern": each(validatePattern),
+	"oneof":   each(validateOneOf),
+	"email":   each(validateEmail),
+	"url":     each(validateURL),
+}
+
+var (
+	errNotEmail = errors.New("must be an email address")
+	errNotURL   = errors.New("must be an absolute URL")
+)
+
+var validators = struct {
+	sync.RWMutex
+	m map[string]Validator
+}{m: map[string]Validator{}}
+
+// checks check the argument of builtin validators and
+// whether they support the type of the field.
+var checks = map[string]func(t reflect.Type, arg string) error{
+	"min":     checkNumber,
+	"max":     checkNumber,
+	"minlen":  checkLength,
+	"maxlen":  checkLength,
+	"pattern": checkPattern,
+	"oneof":   func(reflect.Type, string) error { return nil },
+	"email":   checkString,
+	"url":     checkString,
+}
+
+var patterns sync.Map // map[string]pattern
+
+// pattern is a compiled regular expression or the error of compiling it.
+type pattern struct {
+	rx  *regexp.Regexp
+	err error
+}
+
+// RegisterValidator registers the custom validator f by name,
+// such that it checks fields that have the option name or name=argument.
+// Registering the same name again replaces the validator.
+// Validators must be registered before the first use of a type that names them.
+// Panics if the name is empty, contains a comma or equals sign,
+// or is the name of a builtin validator or option.
+func RegisterValidator(name string, f Validator) {
+	_, builtin := builtins[name]
+	if name == "" || strings.ContainsAny(name, ",=") || builtin ||
+		name == "required" || name == "omitempty" {
+		panic("formdata: invalid validator name " + strconv.Quote(name))
+	}
+	validators.Lock()
+	validators.m[name] = f
+	validators.Unlock()
+}
+
+// checkOptions panics if the options of f name an unknown validator
+// with an argument or a validator that rejects its argument or the type of f.
+// Unknown options without an argument are ignored.
+func checkOptions(f field) {
+	for _, opt := range splitOptions(f.opts) {
+		name, arg, hasArg := strings.Cut(opt, "=")
+		if name == "required" || name == "omitempty" {
+			continue
+		}
+
+		var err error
+		if check, ok := checks[name]; ok {
+			err = check(f.typ, arg)
+		} else {
+			validators.RLock()
+			_, ok = validators.m[name]
+			validators.RUnlock()
+			if !ok && hasArg {
+				err = errors.New("unknown validator")
+			}
+		}
+		if err != nil {
+			panic(fmt.Sprintf("formdata: invalid option %q of field %q: %v", opt, f.name, err))
+		}
+	}
+}
+
+// validate checks the decoded value v of key against the validators in opts.
+func (d *decoder) validate(v reflect.Value, key string, vals []string, opts string) {
+	// deref pointer
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	for _, opt := range splitOptions(opts) {
+		name, arg, _ := strings.Cut(opt, "=")
+		if name == "required" || name == "omitempty" {
+			continue
+		}
+
+		if f, ok := builtins[name]; ok {
+			if err := f(v, arg); err != nil {
+				d.fail(key, vals, name, err)
+			}
+			continue
+		}
+
+		validators.RLock()
+		f, ok := validators.m[name]
+		validators.RUnlock()
+		if !ok { // unknown option
+			continue
+		}
+		if err := f(v.Interface(), arg); err != nil {
+			d.fail(key, vals, name, err)
+		}
+	}
+}
+
+// each returns a validator that applies f to v
+// or to every element of v if it is a slice other than []byte.
+func each(f validator) validator {
+	return func(v reflect.Value, arg string) error {
+		if v.Kind() != reflect.Slice || v.Type().ConvertibleTo(byteSliceType) {
+			return f(v, arg)
+		}
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			for elem.Kind() == reflect.Pointer && !elem.IsNil() {
+				elem = elem.Elem()
+			}
+			if err := f(elem, arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// elemType returns the type that each applies a validator to
+// for fields of type t.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice && !t.ConvertibleTo(byteSliceType) {
+		t = t.Elem()
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+	}
+	return t
+}
+
+func errUnsupported(t reflect.Type) error {
+	return fmt.Errorf("cannot validate unsupported type %q", t)
+}
+
+func checkNumber(t reflect.Type, arg string) error {
+	var err error
+	switch t = elemType(t); t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(arg, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(arg, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(arg, 64)
+	default:
+		err = errUnsupported(t)
+	}
+	return err
+}
+
+func checkLength(t reflect.Type, arg string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		_, err := strconv.Atoi(arg)
+		return err
+	}
+	return errUnsupported(t)
+}
+
+func checkString(t reflect.Type, _ string) error {
+	if t = elemType(t); t.Kind() != reflect.String {
+		return errUnsupported(t)
+	}
+	return nil
+}
+
+func checkPattern(t reflect.Type, arg string) error {
+	if err := checkString(t, arg); err != nil {
+		return err
+	}
+	_, err := compilePattern(arg)
+	return err
+}
+
+// compilePattern compiles the regular expression expr once
+// and caches the result, including the error if it failed.
+func compilePattern(expr string) (*regexp.Regexp, error) {
+	p, ok := patterns.Load(expr)
+	if !ok {
+		rx, err := regexp.Compile(expr)
+		p, _ = patterns.LoadOrStore(expr, pattern{rx, err})
+	}
+	return p.(pattern).rx, p.(pattern).err
+}
+
+// compare compares the number v to arg.
+func compare(v reflect.Value, arg string) (int, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Int(), x), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Uint(), x), nil
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Float(), x), nil
+	}
+	return 0, errUnsupported(v.Type())
+}
+
+var (
+	validateMin = each(func(v reflect.Value, arg string) error {
+		c, err := compare(v, arg)
+		if err == nil && c < 0 {
+			err = fmt.Errorf("must be at least %s", arg)
+		}
+		return err
+	})
+
+	validateMax = each(func(v reflect.Value, arg string) error {
+		c, err := compare(v, arg)
+		if err == nil && c > 0 {
+			err = fmt.Errorf("must be at most %s", arg)
+		}
+		return err
+	})
+)
+
+// length returns the number of characters of a string
+// or the number of elements of a slice or map.
+func length(v reflect.Value) (int, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), nil
+	case reflect.Slice, reflect.Map:
+		return v.Len(), nil
+	}
+	return 0, errUnsupported(v.Type())
+}
+
+func validateMinLen(v reflect.Value, arg string) error {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return err
+	}
+	if l, err := length(v); err != nil {
+		return err
+	} else if l < n {
+		return fmt.Errorf("length must be at least %d", n)
+	}
+	return nil
+}
+
+func validateMaxLen(v reflect.Value, arg string) error {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return err
+	}
+	if l, err := length(v); err != nil {
+		return err
+	} else if l > n {
+		return fmt.Errorf("length must be at most %d", n)
+	}
+	return nil
+}
+
+func validatePattern(v reflect.Value, arg string) error {
+	if v.Kind() != reflect.String {
+		return errUnsupported(v.Type())
+	}
+	rx, err := compilePattern(arg)
+	if err != nil {
+		return err
+	}
+	if !rx.MatchString(v.String()) {
+		return fmt.Errorf("must match pattern %q", arg)
+	}
+	return nil
+}
+
+func validateOneOf(v reflect.Value, arg string) error {
+	s, err := encodeScalar(v)
+	if err != nil {
+		return errUnsupported(v.Type())
+	}
+	if !slices.Contains(strings.Fields(arg), s) {
+		return fmt.Errorf("must be one of %s", arg)
+	}
+	return nil
+}
+
+func validateEmail(v reflect.Value, _ string) error {
+	if v.Kind() != reflect.String {
+		return errUnsupported(v.Type())
+	}
+	addr, err := mail.ParseAddress(v.String())
+	if err != nil || addr.Address != v.String() {
+		return errNotEmail
+	}
+	return nil
+}
+
+func validateURL(v reflect.Value, _ string) error {
+	if v.Kind() != reflect.String {
+		return errUnsupported(v.Type())
+	}
+	u, err := url.Parse(v.String())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errNotURL
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package formdata
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func codes(err error) map[string]string {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	m := map[string]string{}
+	for _, e := range errs {
+		m[e.Field] = e.Code
+	}
+	return m
+}
+
+func TestValidate(t *testing.T) {
+	type form struct {
+		Age     int               `formdata:"age,min=18,max=99"`
+		Score   float64           `formdata:"score,min=0.5"`
+		Count   *uint             `formdata:"count,max=3"`
+		Name    string            `formdata:"name,required,minlen=2,maxlen=4"`
+		Tags    []string          `formdata:"tags,maxlen=2,oneof=a b c"`
+		Nums    []int             `formdata:"nums,min=1"`
+		Color   string            `formdata:"color,oneof=red green"`
+		Email   string            `formdata:"email,email"`
+		Site    string            `formdata:"site,url"`
+		Code    string            `formdata:"code,pattern=^[a-z]{2,3}$"`
+		Items   []item            `formdata:"items,minlen=1"`
+		Meta    map[string]string `formdata:"meta,maxlen=1"`
+		Missing int               `formdata:"missing,min=1"`
+	}
+
+	valid := url.Values{
+		"age":          {"18"},
+		"score":        {"0.5"},
+		"count":        {"3"},
+		"name":         {"Gö"},
+		"tags":         {"a", "c"},
+		"nums":         {"1", "2"},
+		"color":        {"green"},
+		"email":        {"gopher@example.com"},
+		"site":         {"https://go.dev/doc"},
+		"code":         {"abc"},
+		"items[0].qty": {"1"},
+		"meta.a":       {"x"},
+	}
+	var f form
+	require.NoError(t, Unmarshal(valid, &f))
+	require.Equal(t, "abc", f.Code)
+
+	invalid := url.Values{
+		"age":          {"17"},
+		"score":        {"0.25"},
+		"count":        {"4"},
+		"name":         {"Gopher"},
+		"tags":         {"a", "d"},
+		"nums":         {"1", "0"},
+		"color":        {"blue"},
+		"email":        {"Gopher <gopher@example.com>"},
+		"site":         {"/relative"},
+		"code":         {"a,b"},
+		"items[0].qty": {"x"},
+		"meta.a":       {"x"},
+		"meta.b":       {"y"},
+	}
+	err := Unmarshal(invalid, &f)
+	require.Equal(t, map[string]string{
+		"age":          "min",
+		"score":        "min",
+		"count":        "max",
+		"name":         "maxlen",
+		"tags":         "oneof",
+		"nums":         "min",
+		"color":        "oneof",
+		"email":        "email",
+		"site":         "url",
+		"code":         "pattern",
+		"items[0].qty": CodeInvalid,
+		"meta":         "maxlen",
+	}, codes(err))
+
+	err = Unmarshal(url.Values{"age": {"100"}, "name": {"G"}, "tags": {"a", "b", "c"}}, &f)
+	require.Equal(t, map[string]string{
+		"age":  "max",
+		"name": "minlen",
+		"tags": "maxlen",
+	}, codes(err))
+
+	err = Unmarshal(url.Values{}, &f)
+	require.Equal(t, map[string]string{"name": CodeRequired}, codes(err))
+	require.Equal(t, `error in field "name": required but missing`, err.Error())
+}
+
+func TestValidateMessages(t *testing.T) {
+	var f struct {
+		Age  int    `formdata:"age,min=18"`
+		Code string `formdata:"code,pattern=^\\d+$"`
+	}
+	err := Unmarshal(url.Values{"age": {"1"}, "code": {"x"}}, &f)
+	require.Equal(t, "error in field \"age\": must be at least 18\n"+
+		"error in field \"code\": must match pattern \"^\\\\d+$\"", err.Error())
+}
+
+func TestValidateCustom(t *testing.T) {
+	RegisterValidator("even", func(v any, _ string) error {
+		if v.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	RegisterValidator("prefix", func(v any, arg string) error {
+		for _, s := range v.([]string) {
+			if len(s) < len(arg) || s[:len(arg)] != arg {
+				return errors.New("must start with " + arg)
+			}
+		}
+		return nil
+	})
+
+	var f struct {
+		N    *int     `formdata:"n,even"`
+		Keys []string `formdata:"keys,prefix=k_"`
+	}
+	require.NoError(t, Unmarshal(url.Values{"n": {"2"}, "keys": {"k_a"}}, &f))
+	require.Equal(t, 2, *f.N)
+
+	err := Unmarshal(url.Values{
+		"n":    {"3"},
+		"keys": {"k_a", "b"},
+	}, &f)
+	require.Equal(t, map[string]string{
+		"n":    "even",
+		"keys": "prefix",
+	}, codes(err))
+
+	for _, name := range []string{"", "min", "required", "a,b", "a=b"} {
+		var panicked bool
+		func() {
+			defer func() { panicked = recover() != nil }()
+			RegisterValidator(name, nil)
+		}()
+		require.True(t, panicked, name)
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	forms := []any{
+		&struct {
+			X int `formdata:"x,bogus=1"`
+		}{},
+		&struct {
+			X int `formdata:"x,min=abc"`
+		}{},
+		&struct {
+			X uint `formdata:"x,max=-1"`
+		}{},
+		&struct {
+			X []int `formdata:"x,min=1.5"`
+		}{},
+		&struct {
+			X bool `formdata:"x,minlen=1"`
+		}{},
+		&struct {
+			X string `formdata:"x,maxlen=x"`
+		}{},
+		&struct {
+			X int `formdata:"x,email"`
+		}{},
+		&struct {
+			X *string `formdata:"x,pattern=("`
+		}{},
+	}
+	for _, form := range forms {
+		for i := 0; i < 2; i++ {
+			var panicked bool
+			func() {
+				defer func() { panicked = recover() != nil }()
+				_ = Unmarshal(url.Values{"x": {"1"}}, form)
+			}()
+			require.True(t, panicked, form)
+		}
+	}
+
+	// unknown options without an argument are ignored
+	var f struct {
+		X int `formdata:"x,optional,min=1"`
+	}
+	require.NoError(t, Unmarshal(url.Values{"x": {"1"}}, &f))
+	require.Equal(t, 1, f.X)
+
+	_, err1 := compilePattern("(")
+	_, err2 := compilePattern("(")
+	require.True(t, err1 != nil && err1 == err2)
+}
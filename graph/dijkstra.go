@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/xheap"
+)
+
+// Dijkstra computes the shortest paths from src to all other nodes.
+// It returns the distance from src to every node and the predecessor
+// of every node on its shortest path, which can be passed to Path.
+// The predecessor of src is src itself
+// and the predecessor of an unreachable node is -1.
+// All weights must be non-negative.
+// The complexity is O((n+m) log(n)), where m is the number of edges.
+func (g *Graph[W]) Dijkstra(src int) (dist []W, prev []int) {
+	dist = make([]W, len(g.adj))
+	prev = make([]int, len(g.adj))
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[src] = src
+
+	done := densebits.New(len(g.adj))
+
+	var h xheap.Min[int, W]
+	h.Push(src, 0)
+
+	for !h.Empty() {
+		u, d := h.Pop()
+		if done.Get(u) {
+			continue // stale entry
+		}
+		done.Set(u, true)
+
+		for _, e := range g.adj[u] {
+			v := e.To
+			if done.Get(v) {
+				continue
+			}
+			if nd := d + e.Weight; prev[v] < 0 || nd < dist[v] {
+				dist[v] = nd
+				prev[v] = u
+				h.Push(v, nd)
+			}
+		}
+	}
+
+	return dist, prev
+}
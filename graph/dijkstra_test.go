@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestDijkstra(t *testing.T) {
+	g := New[int](5)
+	g.AddArc(0, 1, 4)
+	g.AddArc(0, 2, 1)
+	g.AddArc(2, 1, 2)
+	g.AddArc(1, 3, 1)
+	g.AddArc(3, 0, 1)
+
+	dist, prev := g.Dijkstra(0)
+	require.Equal(t, []int{0, 3, 1, 4, 0}, dist)
+	require.Equal(t, []int{0, 2, 0, 1, -1}, prev)
+	require.Equal(t, []int{0, 2, 1, 3}, Path(prev, 3))
+	require.Equal(t, 0, len(Path(prev, 4)))
+}
+
+func TestDijkstraRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const n = 60
+	g := randomGraph(rnd, n, 300, false)
+
+	// Bellman-Ford as the reference
+	const inf = 1 << 60
+	want := make([]int, n)
+	for i := range want {
+		want[i] = inf
+	}
+	want[0] = 0
+	edges := g.AllEdges()
+	for k := 0; k < n; k++ {
+		for _, e := range edges {
+			if want[e.From] < inf {
+				want[e.To] = min(want[e.To], want[e.From]+e.Weight)
+			}
+		}
+	}
+
+	dist, prev := g.Dijkstra(0)
+	for v := 0; v < n; v++ {
+		if want[v] == inf {
+			require.Equal(t, -1, prev[v])
+			continue
+		}
+		require.Equal(t, want[v], dist[v])
+
+		// the path adds up to the distance
+		path := Path(prev, v)
+		sum := 0
+		for k := 1; k < len(path); k++ {
+			w := inf
+			for _, e := range g.Edges(path[k-1]) {
+				if e.To == path[k] {
+					w = min(w, e.Weight)
+				}
+			}
+			sum += w
+		}
+		require.Equal(t, dist[v], sum)
+	}
+}
@@ -0,0 +1,128 @@
+// Package graph implements common graph algorithms
+// on graphs whose nodes are numbered from 0 to Len()-1.
+package graph
+
+import (
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/queue"
+)
+
+// Weight is a constraint that permits any integer or floating point type.
+type Weight interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Edge is a weighted edge from one node to another.
+type Edge[W Weight] struct {
+	From   int
+	To     int
+	Weight W
+}
+
+// Graph is a weighted directed graph represented by adjacency lists.
+// Undirected graphs are represented by adding an edge in both directions.
+// The zero value is an empty graph ready to use.
+type Graph[W Weight] struct {
+	adj [][]Edge[W]
+}
+
+// New creates a graph with n nodes and no edges.
+func New[W Weight](n int) *Graph[W] {
+	return &Graph[W]{
+		adj: make([][]Edge[W], n),
+	}
+}
+
+// Len reports the number of nodes in g.
+func (g *Graph[W]) Len() int {
+	return len(g.adj)
+}
+
+// AddNode adds a node without edges and returns its index.
+func (g *Graph[W]) AddNode() int {
+	g.adj = append(g.adj, nil)
+	return len(g.adj) - 1
+}
+
+// AddArc adds a directed edge from u to v with weight w.
+func (g *Graph[W]) AddArc(u, v int, w W) {
+	g.adj[u] = append(g.adj[u], Edge[W]{u, v, w})
+}
+
+// AddEdge adds an undirected edge between u and v with weight w
+// by adding a directed edge in both directions.
+func (g *Graph[W]) AddEdge(u, v int, w W) {
+	g.AddArc(u, v, w)
+	if u != v {
+		g.AddArc(v, u, w)
+	}
+}
+
+// Edges returns the outgoing edges of u.
+// The returned slice must not be modified.
+func (g *Graph[W]) Edges(u int) []Edge[W] {
+	return g.adj[u]
+}
+
+// AllEdges returns the edges of all nodes in order of their From node.
+func (g *Graph[W]) AllEdges() []Edge[W] {
+	n := 0
+	for _, edges := range g.adj {
+		n += len(edges)
+	}
+	d := make([]Edge[W], 0, n)
+	for _, edges := range g.adj {
+		d = append(d, edges...)
+	}
+	return d
+}
+
+// BFS visits the nodes reachable from src in breadth-first order
+// and calls f for every node with its distance in edges from src.
+// If f returns false, BFS stops the traversal.
+// The complexity is O(n+m), where m is the number of edges.
+func (g *Graph[W]) BFS(src int, f func(u, depth int) bool) {
+	type item struct{ u, depth int }
+
+	visited := densebits.New(len(g.adj))
+	visited.Set(src, true)
+
+	q := queue.New[item](0)
+	q.Push(item{src, 0})
+
+	for !q.Empty() {
+		x := q.Pop()
+		if !f(x.u, x.depth) {
+			return
+		}
+		for _, e := range g.adj[x.u] {
+			if !visited.Get(e.To) {
+				visited.Set(e.To, true)
+				q.Push(item{e.To, x.depth + 1})
+			}
+		}
+	}
+}
+
+// Path returns the path from the source to dst
+// using the predecessors returned by a shortest path algorithm such as Dijkstra.
+// Returns nil if dst is unreachable.
+func Path(prev []int, dst int) []int {
+	if prev[dst] < 0 {
+		return nil
+	}
+	n := 1
+	for u := dst; prev[u] != u; u = prev[u] {
+		n++
+	}
+	path := make([]int, n)
+	for u := dst; ; u = prev[u] {
+		n--
+		path[n] = u
+		if prev[u] == u {
+			return path
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func randomGraph(rnd *rand.Rand, n, m int, undirected bool) *Graph[int] {
+	g := New[int](n)
+	for k := 0; k < m; k++ {
+		u, v, w := rnd.Intn(n), rnd.Intn(n), rnd.Intn(100)
+		if undirected {
+			g.AddEdge(u, v, w)
+		} else {
+			g.AddArc(u, v, w)
+		}
+	}
+	return g
+}
+
+func TestGraph(t *testing.T) {
+	var g Graph[float64]
+	for i := 0; i < 4; i++ {
+		require.Equal(t, i, g.AddNode())
+	}
+	g.AddArc(0, 1, 0.5)
+	g.AddEdge(1, 2, 1.5)
+	g.AddEdge(3, 3, 2)
+
+	require.Equal(t, 4, g.Len())
+	require.Equal(t, []Edge[float64]{{1, 2, 1.5}}, g.Edges(1))
+	require.Equal(t, []Edge[float64]{
+		{0, 1, 0.5}, {1, 2, 1.5}, {2, 1, 1.5}, {3, 3, 2},
+	}, g.AllEdges())
+}
+
+func TestBFS(t *testing.T) {
+	g := New[int](7)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(1, 3, 1)
+	g.AddEdge(2, 3, 1)
+	g.AddEdge(3, 4, 1)
+	g.AddEdge(5, 6, 1)
+
+	var nodes, depths []int
+	g.BFS(0, func(u, depth int) bool {
+		nodes = append(nodes, u)
+		depths = append(depths, depth)
+		return true
+	})
+	require.Equal(t, []int{0, 1, 2, 3, 4}, nodes)
+	require.Equal(t, []int{0, 1, 1, 2, 3}, depths)
+
+	nodes = nodes[:0]
+	g.BFS(3, func(u, depth int) bool {
+		nodes = append(nodes, u)
+		return depth < 1
+	})
+	require.Equal(t, []int{3, 1}, nodes)
+}
+
+func TestPath(t *testing.T) {
+	prev := []int{0, 0, 1, -1, 2}
+	require.Equal(t, []int{0, 1, 2, 4}, Path(prev, 4))
+	require.Equal(t, []int{0}, Path(prev, 0))
+	require.Equal(t, 0, len(Path(prev, 3)))
+}
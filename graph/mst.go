@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/askeladdk/toolbox/disjoint"
+)
+
+// Kruskal computes a minimum spanning forest of the undirected graph
+// with n nodes and the given edges using Kruskal's algorithm.
+// Every edge is treated as undirected, so both edges
+// that AddEdge adds between two nodes may be passed, as by AllEdges.
+// It returns the edges of the forest in increasing order of weight.
+// The complexity is O(m log(m)), where m is the number of edges.
+func Kruskal[W Weight](n int, edges []Edge[W]) []Edge[W] {
+	sorted := slices.Clone(edges)
+	slices.SortStableFunc(sorted, func(a, b Edge[W]) int {
+		return cmp.Compare(a.Weight, b.Weight)
+	})
+
+	set := disjoint.New(n)
+	var forest []Edge[W]
+	for _, e := range sorted {
+		if set.Union(e.From, e.To) {
+			forest = append(forest, e)
+		}
+	}
+	return forest
+}
+
+// Boruvka computes a minimum spanning forest of the undirected graph
+// with n nodes and the given edges using Borůvka's algorithm.
+// Edges of equal weight are ordered by their index in edges,
+// so the result has the same total weight as that of Kruskal.
+// It returns the edges of the forest in the order that they were added.
+// The complexity is O(m log(n)), where m is the number of edges.
+func Boruvka[W Weight](n int, edges []Edge[W]) []Edge[W] {
+	set := disjoint.New(n)
+	cheapest := make([]int, n)
+	var forest []Edge[W]
+
+	// lighter reports whether the i-th edge is lighter than the j-th.
+	lighter := func(i, j int) bool {
+		if edges[i].Weight != edges[j].Weight {
+			return edges[i].Weight < edges[j].Weight
+		}
+		return i < j
+	}
+
+	for {
+		for i := range cheapest {
+			cheapest[i] = -1
+		}
+
+		// find the cheapest edge leaving every group
+		for i, e := range edges {
+			p, q := set.Find(e.From), set.Find(e.To)
+			if p == q {
+				continue
+			}
+			if c := cheapest[p]; c < 0 || lighter(i, c) {
+				cheapest[p] = i
+			}
+			if c := cheapest[q]; c < 0 || lighter(i, c) {
+				cheapest[q] = i
+			}
+		}
+
+		merged := false
+		for _, i := range cheapest {
+			if i >= 0 && set.Union(edges[i].From, edges[i].To) {
+				forest = append(forest, edges[i])
+				merged = true
+			}
+		}
+
+		if !merged {
+			return forest
+		}
+	}
+}
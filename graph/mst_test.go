@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/disjoint"
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func totalWeight(edges []Edge[int]) int {
+	sum := 0
+	for _, e := range edges {
+		sum += e.Weight
+	}
+	return sum
+}
+
+func TestSpanningForest(t *testing.T) {
+	g := New[int](6)
+	g.AddEdge(0, 1, 7)
+	g.AddEdge(0, 2, 9)
+	g.AddEdge(1, 2, 10)
+	g.AddEdge(1, 3, 15)
+	g.AddEdge(2, 3, 11)
+	g.AddEdge(3, 4, 6)
+	g.AddEdge(4, 4, 1)
+
+	want := []Edge[int]{{3, 4, 6}, {0, 1, 7}, {0, 2, 9}, {2, 3, 11}}
+	require.Equal(t, want, Kruskal(g.Len(), g.AllEdges()))
+	require.Equal(t, 33, totalWeight(Boruvka(g.Len(), g.AllEdges())))
+	require.Equal(t, 0, len(Kruskal[int](3, nil)))
+}
+
+func TestSpanningForestRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for k := 0; k < 20; k++ {
+		n := 1 + rnd.Intn(100)
+		edges := randomGraph(rnd, n, rnd.Intn(3*n), true).AllEdges()
+		kruskal := Kruskal(n, edges)
+		boruvka := Boruvka(n, edges)
+		require.Equal(t, len(kruskal), len(boruvka))
+		require.Equal(t, totalWeight(kruskal), totalWeight(boruvka))
+
+		// the forest connects exactly the components of the graph
+		set := disjoint.New(n)
+		for _, e := range edges {
+			set.Union(e.From, e.To)
+		}
+		require.Equal(t, n-set.CountGroups(), len(boruvka))
+	}
+}
@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"slices"
+
+	"github.com/askeladdk/toolbox/densebits"
+)
+
+// frame is a stack frame of an iterative depth-first search
+// that is visiting the i-th edge of node u.
+type frame struct {
+	u, i int
+}
+
+// TopoSort returns the nodes of g in topological order,
+// such that every edge goes from a node to a node later in the order.
+// Returns false if g has a cycle, in which case no order exists.
+// The complexity is O(n+m), where m is the number of edges.
+func (g *Graph[W]) TopoSort() ([]int, bool) {
+	visited := densebits.New(len(g.adj))
+	done := densebits.New(len(g.adj))
+	order := make([]int, 0, len(g.adj))
+
+	var stack []frame
+	for s := range g.adj {
+		if visited.Get(s) {
+			continue
+		}
+		visited.Set(s, true)
+		stack = append(stack, frame{s, 0})
+
+		for len(stack) > 0 {
+			f := &stack[len(stack)-1]
+			if f.i == len(g.adj[f.u]) {
+				done.Set(f.u, true)
+				order = append(order, f.u)
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			v := g.adj[f.u][f.i].To
+			f.i++
+			switch {
+			case !visited.Get(v):
+				visited.Set(v, true)
+				stack = append(stack, frame{v, 0})
+			case !done.Get(v): // v is on the stack
+				return nil, false
+			}
+		}
+	}
+
+	slices.Reverse(order)
+	return order, true
+}
+
+// SCC returns the strongly connected components of g
+// using Tarjan's algorithm.
+// The components are in reverse topological order,
+// such that no edge goes from a component to an earlier component.
+// The components share a single backing slice of length Len().
+// The complexity is O(n+m), where m is the number of edges.
+func (g *Graph[W]) SCC() [][]int {
+	n := len(g.adj)
+	index := make([]int, n)
+	low := make([]int, n)
+	visited := densebits.New(n)
+	onstack := densebits.New(n)
+	members := make([]int, 0, n)
+
+	var components [][]int
+	var stack []int
+	var frames []frame
+	counter := 0
+
+	visit := func(u int) {
+		index[u], low[u] = counter, counter
+		counter++
+		visited.Set(u, true)
+		onstack.Set(u, true)
+		stack = append(stack, u)
+		frames = append(frames, frame{u, 0})
+	}
+
+	for s := range g.adj {
+		if visited.Get(s) {
+			continue
+		}
+		visit(s)
+
+		for len(frames) > 0 {
+			f := &frames[len(frames)-1]
+			u := f.u
+
+			if f.i < len(g.adj[u]) {
+				v := g.adj[u][f.i].To
+				f.i++
+				switch {
+				case !visited.Get(v):
+					visit(v)
+				case onstack.Get(v):
+					low[u] = min(low[u], index[v])
+				}
+				continue
+			}
+
+			frames = frames[:len(frames)-1]
+			if len(frames) > 0 {
+				p := frames[len(frames)-1].u
+				low[p] = min(low[p], low[u])
+			}
+
+			if low[u] == index[u] { // u is the root of a component
+				off := len(members)
+				for {
+					v := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onstack.Set(v, false)
+					members = append(members, v)
+					if v == u {
+						break
+					}
+				}
+				components = append(components, members[off:len(members):len(members)])
+			}
+		}
+	}
+
+	return components
+}
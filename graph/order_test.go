@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestTopoSort(t *testing.T) {
+	g := New[int](6)
+	g.AddArc(5, 2, 0)
+	g.AddArc(5, 0, 0)
+	g.AddArc(4, 0, 0)
+	g.AddArc(4, 1, 0)
+	g.AddArc(2, 3, 0)
+	g.AddArc(3, 1, 0)
+
+	order, ok := g.TopoSort()
+	require.True(t, ok)
+	require.Equal(t, 6, len(order))
+	pos := make([]int, len(order))
+	for i, u := range order {
+		pos[u] = i
+	}
+	for _, e := range g.AllEdges() {
+		require.True(t, pos[e.From] < pos[e.To])
+	}
+
+	g.AddArc(1, 5, 0)
+	_, ok = g.TopoSort()
+	require.True(t, !ok)
+}
+
+func TestSCC(t *testing.T) {
+	g := New[int](8)
+	g.AddArc(0, 1, 0)
+	g.AddArc(1, 2, 0)
+	g.AddArc(2, 0, 0)
+	g.AddArc(2, 3, 0)
+	g.AddArc(3, 4, 0)
+	g.AddArc(4, 5, 0)
+	g.AddArc(5, 3, 0)
+	g.AddArc(6, 5, 0)
+	g.AddArc(6, 7, 0)
+	g.AddArc(7, 6, 0)
+
+	require.Equal(t, [][]int{{5, 4, 3}, {2, 1, 0}, {7, 6}}, g.SCC())
+}
+
+func TestSCCRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const n = 50
+	g := randomGraph(rnd, n, 80, false)
+
+	// transitive closure as the reference
+	reach := make([][]bool, n)
+	for u := range reach {
+		reach[u] = make([]bool, n)
+		reach[u][u] = true
+		g.BFS(u, func(v, _ int) bool {
+			reach[u][v] = true
+			return true
+		})
+	}
+
+	comp := make([]int, n)
+	seen := 0
+	for c, members := range g.SCC() {
+		for _, u := range members {
+			comp[u] = c
+		}
+		seen += len(members)
+	}
+	require.Equal(t, n, seen)
+
+	for u := 0; u < n; u++ {
+		for v := 0; v < n; v++ {
+			require.Equal(t, reach[u][v] && reach[v][u], comp[u] == comp[v])
+			if reach[u][v] {
+				// reverse topological order
+				require.True(t, comp[u] >= comp[v])
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package rankselect implements a static bit vector
+// that supports rank and select queries.
+package rankselect
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/askeladdk/toolbox/densebits"
+)
+
+const (
+	blockWords  = 8
+	blockBits   = blockWords * 64
+	sampleEvery = 512
+)
+
+// Vector is a static bit vector of n bits that answers rank queries
+// in O(1) and select queries in O(log(n)) worst case.
+// It stores the cumulative population count of every block of 512 bits
+// and the block of every 512th one and zero bit as select hints.
+type Vector struct {
+	bits  densebits.Set
+	n     int
+	ones  int
+	ranks []int
+	sel1  []int
+	sel0  []int
+}
+
+// New builds a Vector over the first n bits of s.
+// The bits of s beyond n must be zero.
+// The Vector shares memory with s, which must not be modified afterwards.
+func New(s densebits.Set, n int) *Vector {
+	v := &Vector{bits: s, n: n}
+	nblocks := (len(s) + blockWords - 1) / blockWords
+	v.ranks = make([]int, nblocks+1)
+	for b := 0; b < nblocks; b++ {
+		v.ranks[b+1] = v.ranks[b] + s.Slice(b*blockBits, min((b+1)*blockBits, len(s)*64)).OnesCount()
+	}
+	v.ones = v.ranks[nblocks]
+
+	v.sel1 = make([]int, 0, v.ones/sampleEvery+1)
+	v.sel0 = make([]int, 0, (n-v.ones)/sampleEvery+1)
+	for b := 0; b < nblocks; b++ {
+		for len(v.sel1)*sampleEvery < v.ranks[b+1] {
+			v.sel1 = append(v.sel1, b)
+		}
+		for len(v.sel0)*sampleEvery < min((b+1)*blockBits, n)-v.ranks[b+1] {
+			v.sel0 = append(v.sel0, b)
+		}
+	}
+	return v
+}
+
+// Len reports the number of bits in v.
+func (v *Vector) Len() int {
+	return v.n
+}
+
+// Ones reports the number of one bits in v.
+func (v *Vector) Ones() int {
+	return v.ones
+}
+
+// Get reports whether the i-th bit is set to one.
+func (v *Vector) Get(i int) bool {
+	return v.bits.Get(i)
+}
+
+// Bits returns the underlying bit set.
+func (v *Vector) Bits() densebits.Set {
+	return v.bits
+}
+
+// Rank1 reports the number of one bits in the range [0, i).
+func (v *Vector) Rank1(i int) int {
+	b := i / blockBits
+	r := v.ranks[b]
+	w := i / 64
+	for j := b * blockWords; j < w; j++ {
+		r += bits.OnesCount64(v.bits[j])
+	}
+	if off := i % 64; off != 0 {
+		r += bits.OnesCount64(v.bits[w] << (64 - off))
+	}
+	return r
+}
+
+// Rank0 reports the number of zero bits in the range [0, i).
+func (v *Vector) Rank0(i int) int {
+	return i - v.Rank1(i)
+}
+
+// Select1 returns the position of the k-th one bit,
+// counting from zero.
+// Panics if k is out of range.
+func (v *Vector) Select1(k int) int {
+	if k < 0 || k >= v.ones {
+		panic("rankselect: select index out of range")
+	}
+	lo, hi := v.hint(v.sel1, k)
+	b := lo + sort.Search(hi-lo, func(j int) bool {
+		return v.ranks[lo+j+1] > k
+	})
+	k -= v.ranks[b]
+	for w := b * blockWords; ; w++ {
+		x := v.bits[w]
+		if c := bits.OnesCount64(x); k >= c {
+			k -= c
+			continue
+		}
+		return w*64 + selectword(x, k)
+	}
+}
+
+// Select0 returns the position of the k-th zero bit,
+// counting from zero.
+// Panics if k is out of range.
+func (v *Vector) Select0(k int) int {
+	if k < 0 || k >= v.n-v.ones {
+		panic("rankselect: select index out of range")
+	}
+	lo, hi := v.hint(v.sel0, k)
+	b := lo + sort.Search(hi-lo, func(j int) bool {
+		return (lo+j+1)*blockBits-v.ranks[lo+j+1] > k
+	})
+	k -= b*blockBits - v.ranks[b]
+	for w := b * blockWords; ; w++ {
+		x := ^v.bits[w]
+		if c := bits.OnesCount64(x); k >= c {
+			k -= c
+			continue
+		}
+		return w*64 + selectword(x, k)
+	}
+}
+
+// hint returns the range of blocks [lo, hi) that contains
+// the k-th bit according to the samples.
+func (v *Vector) hint(samples []int, k int) (lo, hi int) {
+	j := k / sampleEvery
+	lo = samples[j]
+	hi = len(v.ranks) - 1
+	if j+1 < len(samples) {
+		hi = samples[j+1] + 1
+	}
+	return lo, hi
+}
+
+// selectword returns the position of the k-th one bit in x.
+func selectword(x uint64, k int) int {
+	for ; k > 0; k-- {
+		x &= x - 1
+	}
+	return bits.TrailingZeros64(x)
+}
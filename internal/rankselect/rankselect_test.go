@@ -0,0 +1,63 @@
+package rankselect
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestRankSelect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+
+	for _, n := range []int{0, 1, 63, 64, 65, 511, 512, 513, 5000, 100000} {
+		for _, density := range []float64{0.01, 0.5, 0.99} {
+			s := densebits.New(n)
+			for i := 0; i < n; i++ {
+				if rnd.Float64() < density {
+					s.Set(i, true)
+				}
+			}
+
+			v := New(s, n)
+			require.Equal(t, n, v.Len())
+			require.Equal(t, s.OnesCount(), v.Ones())
+
+			var ones, zeros int
+			for i := 0; i < n; i++ {
+				require.Equal(t, ones, v.Rank1(i))
+				require.Equal(t, zeros, v.Rank0(i))
+				if v.Get(i) {
+					require.Equal(t, i, v.Select1(ones))
+					ones++
+				} else {
+					require.Equal(t, i, v.Select0(zeros))
+					zeros++
+				}
+			}
+			require.Equal(t, ones, v.Rank1(n))
+			require.Equal(t, zeros, v.Rank0(n))
+		}
+	}
+}
+
+func TestSelectOutOfRange(t *testing.T) {
+	s := densebits.New(100)
+	s.SetRange(0, 50)
+	v := New(s, 100)
+
+	var panicked bool
+	func() {
+		defer func() { panicked = recover() != nil }()
+		v.Select1(50)
+	}()
+	require.True(t, panicked)
+
+	panicked = false
+	func() {
+		defer func() { panicked = recover() != nil }()
+		v.Select0(50)
+	}()
+	require.True(t, panicked)
+}
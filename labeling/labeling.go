@@ -0,0 +1,173 @@
+// Package labeling implements connected-component labeling of 2D grids,
+// such as binary images, using the two-pass union find algorithm.
+//
+// Cells are addressed by (x, y) coordinates with 0 <= x < width and 0 <= y < height
+// and are stored in row-major order, such that cell (x, y) is at index y*width+x.
+package labeling
+
+import (
+	"image"
+
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/disjoint"
+)
+
+// References:
+// Connected-component labeling
+// https://en.wikipedia.org/wiki/Connected-component_labeling
+
+// Connectivity determines which neighbouring cells are connected.
+type Connectivity int
+
+const (
+	// Four connects cells that share an edge.
+	Four Connectivity = 4
+
+	// Eight connects cells that share an edge or a corner.
+	Eight Connectivity = 8
+)
+
+// Component describes a connected component.
+type Component struct {
+	// Size is the number of cells in the component.
+	Size int
+
+	// Bounds is the smallest rectangle that contains all cells of the component.
+	Bounds image.Rectangle
+}
+
+// Labeling is the result of labeling a grid.
+type Labeling struct {
+	// Width and Height are the dimensions of the grid.
+	Width, Height int
+
+	// Labels holds the label of every cell in row-major order.
+	// Background cells are labeled 0 and the cells of the k-th component
+	// are labeled k, numbering from 1 in the order that components
+	// are first encountered in row-major order.
+	Labels []int
+
+	// Components describes every component
+	// such that Components[k-1] describes the component labeled k.
+	Components []Component
+}
+
+// At returns the label of cell (x, y).
+func (l *Labeling) At(x, y int) int {
+	return l.Labels[y*l.Width+x]
+}
+
+// Label labels the connected components of the one bits of mask,
+// where the bit at index y*width+x corresponds to cell (x, y).
+// Label panics if mask has fewer than width*height bits.
+// The complexity is O(n), where n = width*height.
+func Label(width, height int, mask densebits.Set, conn Connectivity) *Labeling {
+	if mask.Len() < width*height {
+		panic("labeling: mask is smaller than the grid")
+	}
+	return LabelFunc(width, height, func(x, y int) bool {
+		return mask.Get(y*width + x)
+	}, conn)
+}
+
+// LabelFunc labels the connected components of the cells (x, y)
+// for which f(x, y) reports true.
+// f is called exactly once for every cell in row-major order.
+// LabelFunc panics if conn is not Four or Eight.
+// The complexity is O(n), where n = width*height.
+func LabelFunc(width, height int, f func(x, y int) bool, conn Connectivity) *Labeling {
+	if conn != Four && conn != Eight {
+		panic("labeling: invalid connectivity")
+	}
+
+	labels := make([]int, width*height)
+
+	// First pass: assign provisional labels to foreground cells
+	// and record the equivalences between them.
+	// Provisional label 0 is reserved for the background.
+	set := disjoint.New(1)
+	for y := 0; y < height; y++ {
+		row := labels[y*width : (y+1)*width]
+		var above []int
+		if y > 0 {
+			above = labels[(y-1)*width : y*width]
+		}
+
+		for x := range row {
+			if !f(x, y) {
+				continue
+			}
+
+			// labels of the neighbours that have already been visited
+			var nb [4]int
+			if x > 0 {
+				nb[0] = row[x-1]
+			}
+			if above != nil {
+				nb[1] = above[x]
+				if conn == Eight {
+					if x > 0 {
+						nb[2] = above[x-1]
+					}
+					if x+1 < width {
+						nb[3] = above[x+1]
+					}
+				}
+			}
+
+			label := 0
+			for _, k := range nb {
+				switch {
+				case k == 0:
+				case label == 0:
+					label = k
+				default:
+					set.Union(label, k)
+				}
+			}
+
+			if label == 0 {
+				label = len(set)
+				set = append(set, 1)
+			}
+			row[x] = label
+		}
+	}
+
+	// Second pass: replace provisional labels by dense final labels
+	// and measure the components.
+	final := make([]int, len(set))
+	var components []Component
+	for y := 0; y < height; y++ {
+		row := labels[y*width : (y+1)*width]
+		for x, k := range row {
+			if k == 0 {
+				continue
+			}
+
+			r := set.Find(k)
+			if final[r] == 0 {
+				components = append(components, Component{
+					Bounds: image.Rect(x, y, x+1, y+1),
+				})
+				final[r] = len(components)
+			}
+
+			label := final[r]
+			row[x] = label
+
+			c := &components[label-1]
+			c.Size++
+			c.Bounds.Min.X = min(c.Bounds.Min.X, x)
+			c.Bounds.Max.X = max(c.Bounds.Max.X, x+1)
+			c.Bounds.Max.Y = y + 1
+		}
+	}
+
+	return &Labeling{
+		Width:      width,
+		Height:     height,
+		Labels:     labels,
+		Components: components,
+	}
+}
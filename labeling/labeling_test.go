@@ -0,0 +1,136 @@
+package labeling
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func parseGrid(rows ...string) (int, int, densebits.Set) {
+	w, h := len(rows[0]), len(rows)
+	mask := densebits.New(w * h)
+	for y, row := range rows {
+		for x, c := range row {
+			mask.Set(y*w+x, c == '#')
+		}
+	}
+	return w, h, mask
+}
+
+func TestLabelFour(t *testing.T) {
+	w, h, mask := parseGrid(
+		"##..#",
+		".#.##",
+		"#...#",
+		"#.#..",
+	)
+
+	l := Label(w, h, mask, Four)
+	require.Equal(t, []int{
+		1, 1, 0, 0, 2,
+		0, 1, 0, 2, 2,
+		3, 0, 0, 0, 2,
+		3, 0, 4, 0, 0,
+	}, l.Labels)
+	require.Equal(t, []Component{
+		{Size: 3, Bounds: image.Rect(0, 0, 2, 2)},
+		{Size: 4, Bounds: image.Rect(3, 0, 5, 3)},
+		{Size: 2, Bounds: image.Rect(0, 2, 1, 4)},
+		{Size: 1, Bounds: image.Rect(2, 3, 3, 4)},
+	}, l.Components)
+	require.Equal(t, 2, l.At(4, 2))
+	require.Equal(t, 0, l.At(1, 3))
+}
+
+func TestLabelEight(t *testing.T) {
+	// the U shape requires merging two provisional labels
+	w, h, mask := parseGrid(
+		"#..#.",
+		"#..#.",
+		".##..",
+		"....#",
+	)
+
+	l := Label(w, h, mask, Eight)
+	require.Equal(t, []int{
+		1, 0, 0, 1, 0,
+		1, 0, 0, 1, 0,
+		0, 1, 1, 0, 0,
+		0, 0, 0, 0, 2,
+	}, l.Labels)
+	require.Equal(t, []Component{
+		{Size: 6, Bounds: image.Rect(0, 0, 4, 3)},
+		{Size: 1, Bounds: image.Rect(4, 3, 5, 4)},
+	}, l.Components)
+
+	l = Label(w, h, mask, Four)
+	require.Equal(t, 4, len(l.Components))
+}
+
+func TestLabelFuncFlood(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const w, h = 97, 61
+	grid := make([]bool, w*h)
+	for i := range grid {
+		grid[i] = rnd.Intn(5) < 2
+	}
+
+	for _, conn := range []Connectivity{Four, Eight} {
+		l := LabelFunc(w, h, func(x, y int) bool { return grid[y*w+x] }, conn)
+
+		// flood fill every component and compare
+		seen := make([]bool, w*h)
+		next := 0
+		for i := range grid {
+			if !grid[i] || seen[i] {
+				continue
+			}
+			next++
+			require.Equal(t, next, l.Labels[i])
+
+			size := 0
+			bounds := image.Rectangle{}
+			stack := []image.Point{{i % w, i / w}}
+			seen[i] = true
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				require.Equal(t, next, l.At(p.X, p.Y))
+				size++
+				bounds = bounds.Union(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if conn == Four && dx != 0 && dy != 0 {
+							continue
+						}
+						q := image.Pt(p.X+dx, p.Y+dy)
+						if q.In(image.Rect(0, 0, w, h)) && grid[q.Y*w+q.X] && !seen[q.Y*w+q.X] {
+							seen[q.Y*w+q.X] = true
+							stack = append(stack, q)
+						}
+					}
+				}
+			}
+
+			require.Equal(t, Component{Size: size, Bounds: bounds}, l.Components[next-1])
+		}
+		require.Equal(t, next, len(l.Components))
+	}
+}
+
+func TestLabelPanics(t *testing.T) {
+	for _, f := range []func(){
+		func() { Label(10, 10, densebits.New(64), Four) },
+		func() { LabelFunc(2, 2, func(x, y int) bool { return true }, 6) },
+	} {
+		var panicked bool
+		func() {
+			defer func() { panicked = recover() != nil }()
+			f()
+		}()
+		require.True(t, panicked)
+	}
+}
@@ -0,0 +1,227 @@
+package sparsebits
+
+const (
+	opUnion = iota
+	opIntersection
+	opDifference
+)
+
+// Union stores the union of p and q in s.
+// If p and q have different lengths, the result has the length of the longer one.
+// The result is built in a freshly compacted tree
+// so it is safe for s to be the same set as p or q.
+// The complexity is O(n) in the number of allocated nodes of p and q.
+func (s *Set) Union(p, q *Set) {
+	s.combine(p, q, opUnion)
+}
+
+// Intersection stores the intersection of p and q in s.
+// If p and q have different lengths, the result has the length of the longer one.
+// The result is built in a freshly compacted tree
+// so it is safe for s to be the same set as p or q.
+// The complexity is O(n) in the number of allocated nodes of p and q.
+func (s *Set) Intersection(p, q *Set) {
+	s.combine(p, q, opIntersection)
+}
+
+// Difference stores the bits of p that are not set in q in s.
+// If p and q have different lengths, the result has the length of the longer one.
+// The result is built in a freshly compacted tree
+// so it is safe for s to be the same set as p or q.
+// The complexity is O(n) in the number of allocated nodes of p and q.
+func (s *Set) Difference(p, q *Set) {
+	s.combine(p, q, opDifference)
+}
+
+// Equal reports whether s and p have the same one bits,
+// regardless of their lengths.
+// The complexity is O(n) in the number of allocated nodes of s and p.
+func (s *Set) Equal(p *Set) bool {
+	if s.mid < p.mid {
+		s, p = p, s
+	}
+	// descend the larger tree until it covers the same range as the smaller,
+	// whereby everything outside that range must be zero
+	at, mid := uint64(0), s.mid
+	for mid > p.mid {
+		if x := s.dat[at|1]; x != 0 && !emptyNode(s, x, mid>>1) {
+			return false
+		}
+		if at, mid = s.dat[at], mid>>1; at == 0 {
+			return emptyNode(p, 0, p.mid)
+		}
+	}
+	return equalNode(s, at, p, 0, mid)
+}
+
+func (s *Set) combine(p, q *Set, op int) {
+	dat := make([]uint64, 2, 2+max(len(p.dat), len(q.dat)))
+	switch {
+	case p.mid == q.mid:
+		combineNode(&dat, 0, p, 0, q, 0, p.mid, op)
+	case p.mid > q.mid:
+		combineSpine(&dat, 0, p, 0, q, p.mid, op, false)
+	default:
+		combineSpine(&dat, 0, q, 0, p, q.mid, op, true)
+	}
+	s.dat = dat
+	s.mid = max(p.mid, q.mid)
+	s.free = 0
+	s.nfree = 0
+	s.rebuilt()
+}
+
+// combineSpine is like combineNode for operands of different lengths.
+// It descends the left spine of the longer set t from node tat
+// until it covers the same range as the root of the shorter set h,
+// whereby the subtrees right of the spine are combined with zero bits.
+// swapped reports whether t is the right operand q of op.
+func combineSpine(d *[]uint64, at uint64, t *Set, tat uint64, h *Set, mid uint64, op int, swapped bool) bool {
+	// keep the bits of t or h that are combined with zero bits
+	keept := op == opUnion || op == opDifference && !swapped
+	keeph := op == opUnion || op == opDifference && swapped
+
+	var r uint64
+	switch x := t.dat[tat]; {
+	case x == 0:
+		if keeph {
+			r = liftNode(d, h, mid>>1)
+		}
+	case mid>>1 == h.mid:
+		r = uint64(len(*d))
+		*d = append(*d, 0, 0)
+		var nonzero bool
+		if swapped {
+			nonzero = combineNode(d, r, h, 0, t, x, h.mid, op)
+		} else {
+			nonzero = combineNode(d, r, t, x, h, 0, h.mid, op)
+		}
+		if !nonzero {
+			*d, r = (*d)[:r], 0
+		}
+	default:
+		r = uint64(len(*d))
+		*d = append(*d, 0, 0)
+		if !combineSpine(d, r, t, x, h, mid>>1, op, swapped) {
+			*d, r = (*d)[:r], 0
+		}
+	}
+	(*d)[at] = r
+
+	if x := t.dat[tat|1]; x != 0 && keept {
+		(*d)[at|1] = copyNode(d, t, x, mid>>1)
+	}
+	return (*d)[at]|(*d)[at|1] != 0
+}
+
+// liftNode appends a compacted copy of s to d as the leftmost node
+// of height mid, which is at least the height of the root of s,
+// and returns its index or zero if s has no one bits.
+func liftNode(d *[]uint64, s *Set, mid uint64) uint64 {
+	if mid == s.mid {
+		return copyNode(d, s, 0, mid)
+	}
+	r := uint64(len(*d))
+	*d = append(*d, 0, 0)
+	if (*d)[r] = liftNode(d, s, mid>>1); (*d)[r] == 0 {
+		*d = (*d)[:r]
+		return 0
+	}
+	return r
+}
+
+// combineNode stores the result of the children of node pat in p
+// and node qat in q combined by op in node at of d.
+// Reports whether any child of the resulting node is non-zero.
+func combineNode(d *[]uint64, at uint64, p *Set, pat uint64, q *Set, qat uint64, mid uint64, op int) bool {
+	nonzero := false
+	for c := uint64(0); c < 2; c++ {
+		x, y := p.dat[pat|c], q.dat[qat|c]
+		var r uint64
+		if mid == 64 {
+			switch op {
+			case opUnion:
+				r = x | y
+			case opIntersection:
+				r = x & y
+			case opDifference:
+				r = x &^ y
+			}
+		} else {
+			switch {
+			case x != 0 && y != 0:
+				r = uint64(len(*d))
+				*d = append(*d, 0, 0)
+				if !combineNode(d, r, p, x, q, y, mid>>1, op) {
+					*d, r = (*d)[:r], 0
+				}
+			case x != 0 && op != opIntersection:
+				r = copyNode(d, p, x, mid>>1)
+			case y != 0 && op == opUnion:
+				r = copyNode(d, q, y, mid>>1)
+			}
+		}
+		(*d)[at|c] = r
+		nonzero = nonzero || r != 0
+	}
+	return nonzero
+}
+
+// copyNode appends a compacted copy of the subtree at node at of s to d
+// and returns its index or zero if the subtree has no one bits.
+func copyNode(d *[]uint64, s *Set, at, mid uint64) uint64 {
+	r := uint64(len(*d))
+	*d = append(*d, 0, 0)
+	nonzero := false
+	for c := uint64(0); c < 2; c++ {
+		x := s.dat[at|c]
+		if mid != 64 && x != 0 {
+			x = copyNode(d, s, x, mid>>1)
+		}
+		(*d)[r|c] = x
+		nonzero = nonzero || x != 0
+	}
+	if !nonzero {
+		*d = (*d)[:r]
+		return 0
+	}
+	return r
+}
+
+// equalNode reports whether the subtrees at node sat of s and node pat of p
+// have the same one bits.
+func equalNode(s *Set, sat uint64, p *Set, pat, mid uint64) bool {
+	for c := uint64(0); c < 2; c++ {
+		x, y := s.dat[sat|c], p.dat[pat|c]
+		switch {
+		case mid == 64:
+			if x != y {
+				return false
+			}
+		case x != 0 && y != 0:
+			if !equalNode(s, x, p, y, mid>>1) {
+				return false
+			}
+		case x != 0:
+			if !emptyNode(s, x, mid>>1) {
+				return false
+			}
+		case y != 0:
+			if !emptyNode(p, y, mid>>1) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// emptyNode reports whether the subtree at node at of s has no one bits.
+func emptyNode(s *Set, at, mid uint64) bool {
+	for c := uint64(0); c < 2; c++ {
+		x := s.dat[at|c]
+		if x != 0 && (mid == 64 || !emptyNode(s, x, mid>>1)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package sparsebits
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func randomSet(rnd *rand.Rand, n, k int) (*Set, map[int]bool) {
+	s := New(n)
+	m := make(map[int]bool)
+	for i := 0; i < k; i++ {
+		x := rnd.Intn(n)
+		s.Set(x, true)
+		m[x] = true
+	}
+	return s, m
+}
+
+func requireBits(t *testing.T, s *Set, m map[int]bool) {
+	t.Helper()
+	count := 0
+	s.Range(func(i int) bool {
+		require.True(t, m[i], "unexpected bit", i)
+		count++
+		return true
+	})
+	require.Equal(t, len(m), count)
+}
+
+func TestAlgebra(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	const n = 1 << 16
+
+	for _, k := range []int{0, 10, 1000} {
+		p, pm := randomSet(rnd, n, k)
+		q, qm := randomSet(rnd, n, 2*k)
+
+		union := make(map[int]bool)
+		intersection := make(map[int]bool)
+		difference := make(map[int]bool)
+		for x := range pm {
+			union[x] = true
+			if qm[x] {
+				intersection[x] = true
+			} else {
+				difference[x] = true
+			}
+		}
+		for x := range qm {
+			union[x] = true
+		}
+
+		var s Set
+		s.Union(p, q)
+		requireBits(t, &s, union)
+		s.Intersection(p, q)
+		requireBits(t, &s, intersection)
+		s.Difference(p, q)
+		requireBits(t, &s, difference)
+
+		// in place
+		p.Union(p, q)
+		requireBits(t, p, union)
+	}
+}
+
+func TestAlgebraCompact(t *testing.T) {
+	p := New(1 << 16)
+	q := New(1 << 16)
+	p.Set(1000, true)
+	q.Set(30000, true)
+
+	var s Set
+	s.Intersection(p, q)
+	require.Equal(t, 2, len(s.dat))
+	require.Equal(t, -1, s.Min())
+
+	p.Set(30000, true)
+	s.Difference(p, q)
+	r := New(1 << 16)
+	r.Set(1000, true)
+	require.True(t, s.Equal(r))
+	require.True(t, len(s.dat) < len(p.dat))
+}
+
+func TestEqual(t *testing.T) {
+	p := New(1000)
+	q := New(1000)
+	require.True(t, p.Equal(q))
+	require.True(t, p.Equal(New(100000)))
+
+	p.Set(500, true)
+	require.True(t, !p.Equal(q))
+	require.True(t, !q.Equal(p))
+
+	p.Set(500, false)
+	require.True(t, p.Equal(q))
+	require.True(t, q.Equal(p))
+
+	p.Set(7, true)
+	q.Set(7, true)
+	require.True(t, p.Equal(q))
+}
+
+func TestAlgebraDifferentLengths(t *testing.T) {
+	// the universe of a grows and stays grown after the bit is cleared
+	a := New(100)
+	a.Set(1000, true)
+	a.Set(1000, false)
+	require.True(t, a.Equal(New(100)))
+	require.True(t, New(100).Equal(a))
+
+	a.Set(5, true)
+	b := New(100)
+	require.True(t, !a.Equal(b))
+	b.Set(5, true)
+	require.True(t, a.Equal(b))
+	b.Set(1<<20, true)
+	require.True(t, !a.Equal(b))
+	require.True(t, !b.Equal(a))
+
+	rnd := rand.New(rand.NewSource(0))
+	long, longm := randomSet(rnd, 1<<16, 500)
+	high := New(1 << 16) // left spine is empty
+	highm := map[int]bool{}
+	for _, i := range []int{1 << 15, 1<<15 + 7, 1<<16 - 1} {
+		high.Set(i, true)
+		highm[i] = true
+	}
+	short, shortm := randomSet(rnd, 1<<8, 50)
+
+	ops := []struct {
+		f func(s, p, q *Set)
+		g func(x, y bool) bool
+	}{
+		{(*Set).Union, func(x, y bool) bool { return x || y }},
+		{(*Set).Intersection, func(x, y bool) bool { return x && y }},
+		{(*Set).Difference, func(x, y bool) bool { return x && !y }},
+	}
+	for _, op := range ops {
+		for _, tall := range []struct {
+			s *Set
+			m map[int]bool
+		}{{long, longm}, {high, highm}} {
+			for _, swap := range []bool{false, true} {
+				p, pm, q, qm := short, shortm, tall.s, tall.m
+				if swap {
+					p, pm, q, qm = q, qm, p, pm
+				}
+				var s Set
+				op.f(&s, p, q)
+				want := map[int]bool{}
+				for _, m := range []map[int]bool{pm, qm} {
+					for i := range m {
+						if op.g(pm[i], qm[i]) {
+							want[i] = true
+						}
+					}
+				}
+				requireBits(t, &s, want)
+				require.Equal(t, 1<<16, s.Len())
+			}
+		}
+	}
+
+	// the operands are not modified
+	require.Equal(t, 1<<8, short.Len())
+	requireBits(t, short, shortm)
+	requireBits(t, long, longm)
+}
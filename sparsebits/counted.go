@@ -0,0 +1,177 @@
+package sparsebits
+
+import (
+	"math"
+	"math/bits"
+)
+
+// NewCounted is like [New] but returns a set in counted mode.
+// See [Set.EnableCounts].
+func NewCounted(n int) *Set {
+	s := New(n)
+	s.EnableCounts()
+	return s
+}
+
+// EnableCounts switches s to counted mode, in which every interior node
+// caches the population count of its subtree.
+// This makes OnesCount O(1) and Rank, CountRange and Select O(log(n))
+// at the cost of one extra word of memory per node.
+// Set and Flip remain O(log(n)).
+// The complexity is O(n) in the number of allocated nodes.
+func (s *Set) EnableCounts() {
+	if s.cnt == nil {
+		s.recount()
+	}
+}
+
+// Counted reports whether s is in counted mode.
+func (s *Set) Counted() bool {
+	return s.cnt != nil
+}
+
+// Rank reports the number of one bits in the range [0, i).
+// The complexity is O(log(n)) in counted mode and O(n) otherwise.
+func (s *Set) Rank(i int) int {
+	if i <= 0 {
+		return 0
+	}
+	if s.cnt == nil {
+		count := 0
+		s.Range(func(j int) bool {
+			if j >= i {
+				return false
+			}
+			count++
+			return true
+		})
+		return count
+	}
+	idx := uint64(i)
+	if !s.contains(idx) {
+		return s.OnesCount()
+	}
+	count := 0
+	at := uint64(0)
+	for mid := s.mid; ; mid >>= 1 {
+		if idx >= mid {
+			count += s.slotCount(at, mid)
+			idx -= mid
+			at++
+		}
+		x := s.dat[at]
+		if mid == 64 {
+			return count + bits.OnesCount64(x&(1<<idx-1))
+		} else if x == 0 {
+			return count
+		}
+		at = x
+	}
+}
+
+// CountRange reports the number of one bits in the range [lo, hi).
+// The complexity is O(log(n)) in counted mode and O(n) otherwise.
+func (s *Set) CountRange(lo, hi int) int {
+	if lo >= hi {
+		return 0
+	}
+	return s.Rank(hi) - s.Rank(lo)
+}
+
+// Select returns the index of the k-th one bit counting from zero,
+// or -1 if s has at most k one bits.
+// Bits at index math.MaxInt and above are not found.
+// The complexity is O(log(n)) in counted mode and O(n) otherwise.
+func (s *Set) Select(k int) int {
+	if k < 0 {
+		return -1
+	}
+	if s.cnt == nil {
+		r := -1
+		s.Range(func(i int) bool {
+			if k == 0 {
+				r = i
+				return false
+			}
+			k--
+			return true
+		})
+		return r
+	}
+	if k >= s.OnesCount() {
+		return -1
+	}
+	rem := uint64(k)
+	base := uint64(0)
+	at := uint64(0)
+	for mid := s.mid; ; mid >>= 1 {
+		if c := uint64(s.slotCount(at, mid)); rem >= c {
+			rem -= c
+			base += mid
+			at++
+		}
+		x := s.dat[at]
+		if mid == 64 {
+			for ; rem > 0; rem-- {
+				x &= x - 1
+			}
+			if r := base + uint64(bits.TrailingZeros64(x)); r < math.MaxInt {
+				return int(r)
+			}
+			return -1
+		}
+		at = x
+	}
+}
+
+// slotCount reports the population count of the child at slot
+// of a node that covers the range of 2*mid bits.
+func (s *Set) slotCount(slot, mid uint64) int {
+	x := s.dat[slot]
+	if mid == 64 {
+		return bits.OnesCount64(x)
+	} else if x == 0 {
+		return 0
+	}
+	return int(s.cnt[x>>1])
+}
+
+// count increments or decrements the population counts
+// of the root and of the nodes pointed to by the slots in path.
+func (s *Set) count(inc bool, path []uint64) {
+	delta := uint64(1)
+	if !inc {
+		delta = ^uint64(0) // -1
+	}
+	s.cnt[0] += delta
+	for _, slot := range path {
+		s.cnt[s.dat[slot]>>1] += delta
+	}
+}
+
+// rebuilt recomputes the population counts in counted mode
+// after the tree has been rebuilt.
+func (s *Set) rebuilt() {
+	if s.cnt != nil {
+		s.recount()
+	}
+}
+
+func (s *Set) recount() {
+	s.cnt = make([]uint64, len(s.dat)/2)
+	s.recountNode(0, s.mid)
+}
+
+func (s *Set) recountNode(at, mid uint64) uint64 {
+	var count uint64
+	for c := uint64(0); c < 2; c++ {
+		x := s.dat[at|c]
+		if mid == 64 {
+			count += uint64(bits.OnesCount64(x))
+		} else if x != 0 {
+			count += s.recountNode(x, mid>>1)
+		}
+	}
+	s.cnt[at>>1] = count
+	return count
+}
@@ -0,0 +1,100 @@
+package sparsebits
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestCountedRankSelect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	c := NewCounted(1000)
+	u := New(1000)
+	require.True(t, c.Counted())
+	require.True(t, !u.Counted())
+
+	for n := 0; n < 5000; n++ {
+		x := rnd.Intn(1 << 16)
+		switch rnd.Intn(3) {
+		case 0:
+			c.Set(x, true)
+			u.Set(x, true)
+		case 1:
+			c.Set(x, false)
+			u.Set(x, false)
+		case 2:
+			c.Flip(x)
+			u.Flip(x)
+		}
+	}
+
+	require.Equal(t, u.OnesCount(), c.OnesCount())
+
+	xs := collect(u)
+	for k, x := range xs {
+		require.Equal(t, k, c.Rank(x))
+		require.Equal(t, k+1, c.Rank(x+1))
+		require.Equal(t, x, c.Select(k))
+		require.Equal(t, x, u.Select(k))
+	}
+	require.Equal(t, -1, c.Select(len(xs)))
+	require.Equal(t, -1, u.Select(len(xs)))
+	require.Equal(t, -1, c.Select(-1))
+	require.Equal(t, len(xs), c.Rank(1<<40))
+
+	for n := 0; n < 1000; n++ {
+		lo := rnd.Intn(1<<16 + 100)
+		hi := rnd.Intn(1<<16 + 100)
+		require.Equal(t, u.CountRange(lo, hi), c.CountRange(lo, hi))
+	}
+}
+
+func TestCountedRebuild(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	p, _ := randomSet(rnd, 1<<16, 500)
+	q, _ := randomSet(rnd, 1<<16, 500)
+	want := New(1 << 16)
+	want.Union(p, q)
+
+	s := NewCounted(1 << 16)
+	s.Union(p, q)
+	require.True(t, s.Counted())
+	require.Equal(t, want.OnesCount(), s.OnesCount())
+
+	for _, x := range collect(p) {
+		s.Set(x, false)
+	}
+	s.Compact()
+	require.Equal(t, s.CountRange(0, s.Len()), s.OnesCount())
+	want.Difference(want, p)
+	require.Equal(t, want.OnesCount(), s.OnesCount())
+
+	s.Set(1<<20, true)
+	require.Equal(t, want.OnesCount()+1, s.OnesCount())
+	require.Equal(t, 1<<20, s.Select(s.OnesCount()-1))
+
+	data, _ := s.MarshalBinary()
+	r := NewCounted(0)
+	require.NoError(t, r.UnmarshalBinary(data))
+	require.Equal(t, s.OnesCount(), r.OnesCount())
+	require.Equal(t, 1<<20, r.Select(r.OnesCount()-1))
+
+	r.Reset()
+	require.Equal(t, 0, r.OnesCount())
+
+	p.EnableCounts()
+	require.True(t, p.OnesCount() <= 500)
+	require.Equal(t, collect(p)[10], p.Select(10))
+}
+
+func TestCountedSelectAboveMaxInt(t *testing.T) {
+	for _, s := range []*Set{NewCounted(100), New(100)} {
+		s.Set(3, true)
+		s.SetUint64(math.MaxUint64-5, true)
+		require.Equal(t, 3, s.Select(0))
+		require.Equal(t, -1, s.Select(1))
+		require.Equal(t, -1, s.Select(2))
+	}
+}
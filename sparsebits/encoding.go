@@ -0,0 +1,218 @@
+package sparsebits
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/askeladdk/toolbox/densebits"
+)
+
+var errInvalidBinary = errors.New("sparsebits: invalid binary encoding")
+
+// FromSorted returns a new sparse bit set with the bits at the indexes in xs set to one.
+// The indexes must be sorted in increasing order but may contain duplicates.
+// The tree is built in a single bulk pass and is fully compacted.
+// Panics if xs is not sorted or contains negative indexes.
+// The complexity is O(k log(n)) where k is len(xs).
+func FromSorted(xs []int) *Set {
+	if !slices.IsSorted(xs) {
+		panic("sparsebits: indexes are not sorted")
+	}
+	n := 0
+	if len(xs) != 0 {
+		if xs[0] < 0 {
+			panic("sparsebits: negative index")
+		}
+		n = xs[len(xs)-1] + 1
+	}
+	s := New(n)
+	dat := make([]uint64, 2, 2+2*len(xs))
+	buildSorted(&dat, 0, xs, 0, s.mid)
+	s.dat = dat
+	return s
+}
+
+// buildSorted stores the indexes of xs, which all lie in the range [base, base+2*mid),
+// in the children of node at of d.
+func buildSorted(d *[]uint64, at uint64, xs []int, base, mid uint64) {
+	// split xs into the indexes of the left and right child
+	k := 0
+	for k < len(xs) && uint64(xs[k]) < base+mid {
+		k++
+	}
+	for c, ys := range [2][]int{xs[:k], xs[k:]} {
+		if len(ys) == 0 {
+			continue
+		}
+		lo := base + uint64(c)*mid
+		if mid == 64 {
+			var x uint64
+			for _, y := range ys {
+				x |= 1 << (uint64(y) - lo)
+			}
+			(*d)[at|uint64(c)] = x
+			continue
+		}
+		r := uint64(len(*d))
+		*d = append(*d, 0, 0)
+		(*d)[at|uint64(c)] = r
+		buildSorted(d, r, ys, lo, mid>>1)
+	}
+}
+
+// FromDense returns a new sparse bit set with the same bits as p.
+// The tree is built in a single bulk pass and is fully compacted.
+// The complexity is O(n).
+func FromDense(p densebits.Set) *Set {
+	s := New(p.Len())
+	dat := make([]uint64, 2)
+	buildDense(&dat, 0, p, 0, s.mid)
+	s.dat = slices.Clip(dat)
+	return s
+}
+
+// buildDense stores the bits of p in the range [base, base+2*mid)
+// in the children of node at of d and reports whether any of them is non-zero.
+func buildDense(d *[]uint64, at uint64, p densebits.Set, base, mid uint64) bool {
+	nonzero := false
+	for c := uint64(0); c < 2; c++ {
+		lo := base + c*mid
+		var x uint64
+		if lo/64 >= uint64(len(p)) {
+			break
+		} else if mid == 64 {
+			x = p[lo/64]
+		} else {
+			x = uint64(len(*d))
+			*d = append(*d, 0, 0)
+			if !buildDense(d, x, p, lo, mid>>1) {
+				*d, x = (*d)[:x], 0
+			}
+		}
+		(*d)[at|c] = x
+		nonzero = nonzero || x != 0
+	}
+	return nonzero
+}
+
+// ToDense returns a dense bit set with the same bits as s
+// that is just large enough to hold the highest one bit.
+// The complexity is O(k log(n)) where k is the number of one bits.
+func (s *Set) ToDense() densebits.Set {
+	p := densebits.New(s.Max() + 1)
+	s.Range(func(i int) bool {
+		p.Set(i, true)
+		return true
+	})
+	return p
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler].
+//
+// The binary format starts with one byte holding log2(Len()),
+// followed by the nodes of the tree in pre-order.
+// Every node is encoded as one byte with bit 0 set if the left child is present
+// and bit 1 set if the right child is present, followed by its present children.
+// The children at the lowest level are 64-bit words in little endian byte order.
+// Subtrees that contain only zero bits are omitted.
+func (s *Set) MarshalBinary() ([]byte, error) {
+	b := []byte{byte(bits.TrailingZeros64(s.mid) + 1)}
+	b, _ = s.encodeNode(b, 0, s.mid, true)
+	return b, nil
+}
+
+// encodeNode appends node at to b
+// and reports whether it has any non-zero children.
+// The node is omitted if it has no non-zero children unless keep is true.
+func (s *Set) encodeNode(b []byte, at, mid uint64, keep bool) ([]byte, bool) {
+	n := len(b)
+	b = append(b, 0)
+	var mask byte
+	for c := uint64(0); c < 2; c++ {
+		x := s.dat[at|c]
+		if x == 0 {
+			continue
+		}
+		if mid == 64 {
+			b = binary.LittleEndian.AppendUint64(b, x)
+			mask |= 1 << c
+			continue
+		}
+		var ok bool
+		if b, ok = s.encodeNode(b, x, mid>>1, false); ok {
+			mask |= 1 << c
+		}
+	}
+	if mask == 0 && !keep {
+		return b[:n], false
+	}
+	b[n] = mask
+	return b, mask != 0
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+// It accepts the format produced by [Set.MarshalBinary]
+// and rejects input that is truncated, has trailing bytes
+// or is not in canonical form.
+func (s *Set) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 {
+		return fmt.Errorf("%w: missing header", errInvalidBinary)
+	}
+	shift := int(b[0])
+	if shift < 7 || shift > 64 {
+		return fmt.Errorf("%w: length 2^%d out of range", errInvalidBinary, shift)
+	}
+	mid := uint64(1) << (shift - 1)
+	dat := make([]uint64, 2, 2+len(b)/4)
+	rest, err := decodeNode(&dat, 0, b[1:], mid, true)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", errInvalidBinary, len(rest))
+	}
+	*s = Set{dat: dat, cnt: s.cnt, mid: mid}
+	s.rebuilt()
+	return nil
+}
+
+// decodeNode decodes the children of node at of d from b
+// and returns the remaining bytes.
+func decodeNode(d *[]uint64, at uint64, b []byte, mid uint64, root bool) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("%w: unexpected end of data", errInvalidBinary)
+	}
+	mask := b[0]
+	b = b[1:]
+	if mask > 3 || (mask == 0 && !root) {
+		return nil, fmt.Errorf("%w: invalid node mask %#x", errInvalidBinary, mask)
+	}
+	for c := uint64(0); c < 2; c++ {
+		if mask&(1<<c) == 0 {
+			continue
+		}
+		if mid == 64 {
+			if len(b) < 8 {
+				return nil, fmt.Errorf("%w: unexpected end of data", errInvalidBinary)
+			}
+			x := binary.LittleEndian.Uint64(b)
+			if x == 0 {
+				return nil, fmt.Errorf("%w: zero word", errInvalidBinary)
+			}
+			(*d)[at|c] = x
+			b = b[8:]
+			continue
+		}
+		r := uint64(len(*d))
+		*d = append(*d, 0, 0)
+		(*d)[at|c] = r
+		var err error
+		if b, err = decodeNode(d, r, b, mid>>1, false); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,100 @@
+package sparsebits
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestFromSorted(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	xs := rnd.Perm(100000)[:1000]
+	xs = append(xs, xs[:10]...)
+	slices.Sort(xs)
+
+	s := FromSorted(xs)
+	require.Equal(t, slices.Compact(slices.Clone(xs)), collect(s))
+
+	r := New(s.Len())
+	for _, x := range xs {
+		r.Set(x, true)
+	}
+	require.True(t, s.Equal(r))
+	require.Equal(t, len(r.dat), len(s.dat))
+
+	require.Equal(t, 0, FromSorted(nil).OnesCount())
+
+	var panicked bool
+	func() {
+		defer func() { panicked = recover() != nil }()
+		FromSorted([]int{200, 5})
+	}()
+	require.True(t, panicked)
+}
+
+func TestDenseConversion(t *testing.T) {
+	p := densebits.New(1000)
+	p.SetRange(100, 150)
+	p.Set(999, true)
+
+	s := FromDense(p)
+	require.Equal(t, 51, s.OnesCount())
+	require.Equal(t, 100, s.Min())
+	require.Equal(t, 999, s.Max())
+
+	q := s.ToDense()
+	require.True(t, q.Equal(p))
+
+	require.Equal(t, 0, FromDense(densebits.New(1000)).OnesCount())
+	require.Equal(t, 2, len(FromDense(densebits.New(1000)).dat))
+	require.Equal(t, 0, New(1000).ToDense().Len())
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for _, k := range []int{0, 1, 10, 1000} {
+		s, _ := randomSet(rnd, 1<<20, k)
+		// leave empty subtrees behind
+		s.Set(12345, true)
+		s.Set(12345, false)
+
+		data, err := s.MarshalBinary()
+		require.NoError(t, err)
+
+		var r Set
+		require.NoError(t, r.UnmarshalBinary(data))
+		require.True(t, r.Equal(s))
+		require.Equal(t, s.Len(), r.Len())
+		require.Equal(t, collect(s), collect(&r))
+
+		r.Set(54321, true)
+		require.True(t, r.Get(54321))
+	}
+}
+
+func TestBinaryInvalid(t *testing.T) {
+	s := New(256)
+	s.Set(200, true)
+	data, _ := s.MarshalBinary()
+
+	invalid := [][]byte{
+		nil,
+		{6, 0},
+		{65, 0},
+		data[:len(data)-1],
+		append(slices.Clone(data), 0),
+		{8, 4},
+		{8, 2, 0},
+		{7, 1, 0, 0, 0, 0, 0, 0, 0, 0},
+	}
+
+	for _, b := range invalid {
+		var r Set
+		err := r.UnmarshalBinary(b)
+		require.True(t, errors.Is(err, errInvalidBinary), b, err)
+	}
+}
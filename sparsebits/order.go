@@ -0,0 +1,123 @@
+package sparsebits
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Min returns the index of the lowest one bit in s,
+// or -1 if s has no one bits below math.MaxInt.
+// The complexity is O(log(n)).
+func (s *Set) Min() int {
+	return s.Next(-1)
+}
+
+// Max returns the index of the highest one bit in s below math.MaxInt,
+// or -1 if there is no such bit.
+// The complexity is O(log(n)).
+func (s *Set) Max() int {
+	return s.Prev(s.Len())
+}
+
+// Next returns the index of the lowest one bit in s that is greater than i,
+// or -1 if there is no such bit.
+// Bits at index math.MaxInt and above are not found.
+// The complexity is O(log(n)).
+func (s *Set) Next(i int) int {
+	if i >= s.Len()-1 {
+		return -1
+	}
+	if r, ok := s.next(0, s.mid, 0, uint64(max(i+1, 0))); ok && r < math.MaxInt {
+		return int(r)
+	}
+	return -1
+}
+
+// Prev returns the index of the highest one bit in s that is less than i,
+// or -1 if there is no such bit.
+// The complexity is O(log(n)).
+func (s *Set) Prev(i int) int {
+	if i <= 0 {
+		return -1
+	}
+	if r, ok := s.prev(0, s.mid, 0, uint64(min(i-1, s.Len()-1))); ok {
+		return int(r)
+	}
+	return -1
+}
+
+// Range calls f for the index of every one bit in s in increasing order.
+// If f returns false, Range stops the iteration.
+// Bits at index math.MaxInt and above are not visited.
+// Subtrees that contain only zero bits are skipped.
+// The complexity is O(k log(n)) where k is the number of one bits.
+func (s *Set) Range(f func(i int) bool) {
+	s.walk(0, s.mid, 0, f)
+}
+
+// next returns the lowest one bit that is greater than or equal to i
+// in the subtree at node at that covers the range [base, base+2*mid).
+func (s *Set) next(at, mid, base, i uint64) (uint64, bool) {
+	for c := uint64(0); c < 2; c++ {
+		lo := base + c*mid
+		x := s.dat[at|c]
+		if x == 0 || (i > lo && i-lo >= mid) {
+			continue
+		}
+		if mid == 64 {
+			if i > lo {
+				x &= ^uint64(0) << (i - lo)
+			}
+			if x != 0 {
+				return lo + uint64(bits.TrailingZeros64(x)), true
+			}
+		} else if r, ok := s.next(x, mid>>1, lo, max(i, lo)); ok {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// prev returns the highest one bit that is less than or equal to i
+// in the subtree at node at that covers the range [base, base+2*mid).
+func (s *Set) prev(at, mid, base, i uint64) (uint64, bool) {
+	for c := uint64(2); c > 0; c-- {
+		lo := base + (c-1)*mid
+		x := s.dat[at|(c-1)]
+		if x == 0 || i < lo {
+			continue
+		}
+		if mid == 64 {
+			if i-lo < 63 {
+				x &= ^uint64(0) >> (63 - (i - lo))
+			}
+			if x != 0 {
+				return lo + 63 - uint64(bits.LeadingZeros64(x)), true
+			}
+		} else if r, ok := s.prev(x, mid>>1, lo, min(i, lo+mid-1)); ok {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+func (s *Set) walk(at, mid, base uint64, f func(i int) bool) bool {
+	for c := uint64(0); c < 2; c++ {
+		lo := base + c*mid
+		x := s.dat[at|c]
+		if x == 0 {
+			continue
+		}
+		if mid == 64 {
+			for ; x != 0; x &= x - 1 {
+				i := lo + uint64(bits.TrailingZeros64(x))
+				if i >= math.MaxInt || !f(int(i)) {
+					return false
+				}
+			}
+		} else if !s.walk(x, mid>>1, lo, f) {
+			return false
+		}
+	}
+	return true
+}
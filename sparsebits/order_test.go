@@ -0,0 +1,109 @@
+package sparsebits
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func TestMinMaxEmpty(t *testing.T) {
+	b := New(1000)
+	require.Equal(t, -1, b.Min())
+	require.Equal(t, -1, b.Max())
+	require.Equal(t, -1, b.Next(-1))
+	require.Equal(t, -1, b.Prev(b.Len()))
+
+	b.Set(500, true)
+	b.Set(500, false)
+	require.Equal(t, -1, b.Min())
+	require.Equal(t, -1, b.Max())
+}
+
+func TestNextPrev(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	b := New(100000)
+
+	var xs []int
+	for _, x := range rnd.Perm(b.Len())[:500] {
+		b.Set(x, true)
+		xs = append(xs, x)
+	}
+	slices.Sort(xs)
+
+	require.Equal(t, xs[0], b.Min())
+	require.Equal(t, xs[len(xs)-1], b.Max())
+
+	for n := 0; n < 5000; n++ {
+		i := rnd.Intn(b.Len()+2) - 1
+
+		j, _ := slices.BinarySearch(xs, i+1)
+		expected := -1
+		if j < len(xs) {
+			expected = xs[j]
+		}
+		require.Equal(t, expected, b.Next(i), "Next", i)
+
+		j, _ = slices.BinarySearch(xs, i)
+		expected = -1
+		if j > 0 {
+			expected = xs[j-1]
+		}
+		require.Equal(t, expected, b.Prev(i), "Prev", i)
+	}
+
+	for k, x := range xs {
+		if k+1 < len(xs) {
+			require.Equal(t, xs[k+1], b.Next(x))
+		}
+		if k > 0 {
+			require.Equal(t, xs[k-1], b.Prev(x))
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	b := New(10000)
+
+	var xs []int
+	for _, x := range rnd.Perm(b.Len())[:300] {
+		b.Set(x, true)
+		xs = append(xs, x)
+	}
+	slices.Sort(xs)
+
+	var got []int
+	b.Range(func(i int) bool {
+		got = append(got, i)
+		return true
+	})
+	require.Equal(t, xs, got)
+
+	got = got[:0]
+	b.Range(func(i int) bool {
+		got = append(got, i)
+		return len(got) < 10
+	})
+	require.Equal(t, xs[:10], got)
+}
+
+func TestOrderAboveMaxInt(t *testing.T) {
+	b := New(100)
+	b.SetUint64(math.MaxUint64-5, true)
+	require.Equal(t, -1, b.Min())
+	require.Equal(t, -1, b.Max())
+	require.Equal(t, -1, b.Next(3))
+
+	b.Set(3, true)
+	b.Set(100, true)
+	b.SetUint64(math.MaxInt, true)
+	require.Equal(t, 3, b.Min())
+	require.Equal(t, 100, b.Next(3))
+	require.Equal(t, -1, b.Next(100))
+	require.Equal(t, 100, b.Max())
+	require.Equal(t, 100, b.Prev(math.MaxInt))
+	require.Equal(t, 3, b.Prev(100))
+}
@@ -2,7 +2,9 @@
 package sparsebits
 
 import (
+	"math"
 	"math/bits"
+	"slices"
 )
 
 // Based on:
@@ -11,13 +13,25 @@ import (
 // Set represents a sparse bit set.
 // It is implemented as a binary tree and is best suited when few bits are expected
 // to be set to one and set membership is the only needed operation.
+//
+// Nodes whose subtrees become all zero are released to a free list
+// and reused by later insertions.
+//
+// The universe grows automatically by adding levels on top of the root
+// when a bit beyond Len() is set, up to the full range of uint64 keys.
+// Methods that take or return an int index only address the bits
+// in the range [0, math.MaxInt); use the Uint64 variants for larger keys.
 type Set struct {
-	dat []uint64
-	mid uint64
+	dat   []uint64
+	cnt   []uint64
+	mid   uint64
+	free  uint64
+	nfree int
 }
 
 // New returns a new sparse bitset for bits in range [0, n)
 // where n is rounded up to the nearest power of two.
+// The range is only an initial size hint as the universe grows on demand.
 func New(n int) *Set {
 	have := uint64(128)
 	for have < uint64(n) {
@@ -31,7 +45,11 @@ func New(n int) *Set {
 }
 
 // Len reports the number of bits in s.
+// It is saturated at [math.MaxInt] if the universe has grown beyond it.
 func (s *Set) Len() int {
+	if s.mid > math.MaxInt>>1 {
+		return math.MaxInt
+	}
 	return int(s.mid << 1)
 }
 
@@ -39,27 +57,93 @@ func (s *Set) Len() int {
 // The complexity is O(1).
 func (s *Set) Reset() {
 	s.dat = append(s.dat[:0], 0, 0)
+	s.free = 0
+	s.nfree = 0
+	if s.cnt != nil {
+		s.cnt = append(s.cnt[:0], 0)
+	}
+}
+
+// Compact rebuilds the tree in a new backing slice that contains
+// only the nodes that are needed, and discards the free list.
+// The complexity is O(n) in the number of allocated nodes.
+func (s *Set) Compact() {
+	dat := make([]uint64, 2, len(s.dat)-2*s.nfree)
+	for c := uint64(0); c < 2; c++ {
+		x := s.dat[c]
+		if s.mid != 64 && x != 0 {
+			x = copyNode(&dat, s, x, s.mid>>1)
+		}
+		dat[c] = x
+	}
+	s.dat = slices.Clip(dat)
+	s.free = 0
+	s.nfree = 0
+	s.rebuilt()
+}
+
+// MemoryUsage reports the number of bytes allocated by the tree
+// and how many of those bytes are wasted.
+// Wasted bytes are held by nodes on the free list and by unused capacity;
+// use [Set.Compact] to reclaim them.
+// The complexity is O(1).
+func (s *Set) MemoryUsage() (total, wasted int) {
+	total = 8 * cap(s.dat)
+	wasted = 8 * (cap(s.dat) - len(s.dat) + 2*s.nfree)
+	if s.cnt != nil {
+		total += 8 * cap(s.cnt)
+		wasted += 8 * (cap(s.cnt) - len(s.cnt) + s.nfree)
+	}
+	return total, wasted
 }
 
 // Set sets or clears the i-th bit.
+// The universe grows automatically if i >= Len().
+// Panics if i is negative.
 // The complexity is O(log(n)).
 func (s *Set) Set(i int, to bool) {
+	s.SetUint64(checkindex(i), to)
+}
+
+// Flip sets the i-th bit to one if it zero or to zero it if is one.
+// The universe grows automatically if i >= Len().
+// Panics if i is negative.
+// The complexity is O(log(n)).
+func (s *Set) Flip(i int) {
+	s.FlipUint64(checkindex(i))
+}
+
+// SetUint64 is like [Set.Set] but accepts the full range of uint64 keys.
+// The complexity is O(log(n)).
+func (s *Set) SetUint64(k uint64, to bool) {
 	if to {
-		s.twiddle(i, 1)
+		s.twiddle(k, 1)
 	} else {
-		s.twiddle(i, 0)
+		s.twiddle(k, 0)
 	}
 }
 
-// Flip sets the i-th bit to one if it zero or to zero it if is one.
+// FlipUint64 is like [Set.Flip] but accepts the full range of uint64 keys.
 // The complexity is O(log(n)).
-func (s *Set) Flip(i int) {
-	s.twiddle(i, 2)
+func (s *Set) FlipUint64(k uint64) {
+	s.twiddle(k, 2)
+}
+
+// Grow ensures that s has space for at least n bits
+// by adding levels on top of the root of the tree.
+// The complexity is O(log(n)).
+func (s *Set) Grow(n int) {
+	if n > 0 {
+		s.grow(uint64(n - 1))
+	}
 }
 
 // OnesCount reports the number of one bits (population count) in s.
-// The complexity is O(n).
+// The complexity is O(1) in counted mode and O(n) otherwise.
 func (s *Set) OnesCount() int {
+	if s.cnt != nil {
+		return int(s.cnt[0])
+	}
 	var count int
 	q := make([]uint64, 0, bits.TrailingZeros64(s.mid)<<1-2)
 	q = append(q, 0, s.mid)
@@ -90,9 +174,19 @@ func (s *Set) OnesCount() int {
 }
 
 // Get reports whether the i-th bit is set to one.
+// Indexes outside of the universe are reported as zero.
 // The complexity is O(log(n)).
 func (s *Set) Get(i int) bool {
-	idx := uint64(i)
+	return i >= 0 && s.GetUint64(uint64(i))
+}
+
+// GetUint64 is like [Set.Get] but accepts the full range of uint64 keys.
+// The complexity is O(log(n)).
+func (s *Set) GetUint64(k uint64) bool {
+	if !s.contains(k) {
+		return false
+	}
+	idx := k
 	at := uint64(0)
 	mid := s.mid
 	for ; ; mid >>= 1 {
@@ -111,8 +205,18 @@ func (s *Set) Get(i int) bool {
 	}
 }
 
-func (s *Set) twiddle(i, op int) {
-	idx := uint64(i)
+func (s *Set) twiddle(idx uint64, op int) {
+	if !s.contains(idx) {
+		if op == 0 { // clear
+			return
+		}
+		s.grow(idx)
+	}
+
+	// path records the slots that point to the nodes along the way down,
+	// so that nodes that become zero can be released on the way up.
+	var path [64]uint64
+	depth := 0
 	at := uint64(0)
 	mid := s.mid
 	for ; ; mid >>= 1 {
@@ -123,6 +227,7 @@ func (s *Set) twiddle(i, op int) {
 
 		if mid == 64 {
 			bit := uint64(1) << idx
+			old := s.dat[at]
 			switch op {
 			case 0: // clear
 				s.dat[at] &^= bit
@@ -131,18 +236,91 @@ func (s *Set) twiddle(i, op int) {
 			case 2: // flip
 				s.dat[at] ^= bit
 			}
+			if s.cnt != nil && s.dat[at] != old {
+				s.count(s.dat[at]&bit != 0, path[:depth])
+			}
+			if op != 1 {
+				s.reclaim(at&^1, path[:depth])
+			}
 			return
 		}
 
+		path[depth] = at
+		depth++
+
 		down := s.dat[at]
 		if down == 0 {
 			if op == 0 { // clear
 				return
 			}
-			down = uint64(len(s.dat))
+			down = s.alloc()
 			s.dat[at] = down
-			s.dat = append(s.dat, 0, 0)
 		}
 		at = down
 	}
 }
+
+// reclaim releases node at and its ancestors for as long as they are all zero.
+// The root is never released.
+func (s *Set) reclaim(at uint64, path []uint64) {
+	for n := len(path); n > 0 && s.dat[at] == 0 && s.dat[at|1] == 0; n-- {
+		s.release(at)
+		slot := path[n-1]
+		s.dat[slot] = 0
+		at = slot &^ 1
+	}
+}
+
+// alloc returns the index of a zero node,
+// reusing a node from the free list if possible.
+func (s *Set) alloc() uint64 {
+	if at := s.free; at != 0 {
+		s.free = s.dat[at]
+		s.dat[at] = 0
+		s.nfree--
+		return at
+	}
+	at := uint64(len(s.dat))
+	s.dat = append(s.dat, 0, 0)
+	if s.cnt != nil {
+		s.cnt = append(s.cnt, 0)
+	}
+	return at
+}
+
+// release pushes node at on the free list,
+// which is threaded through the first word of the free nodes.
+func (s *Set) release(at uint64) {
+	s.dat[at] = s.free
+	s.dat[at|1] = 0
+	s.free = at
+	s.nfree++
+}
+
+// contains reports whether k lies within the universe of s.
+func (s *Set) contains(k uint64) bool {
+	return k>>1 < s.mid
+}
+
+// grow adds levels on top of the root until k lies within the universe.
+// The old root becomes the left child of the new root.
+func (s *Set) grow(k uint64) {
+	for !s.contains(k) {
+		if s.dat[0]|s.dat[1] != 0 {
+			at := s.alloc()
+			s.dat[at], s.dat[at|1] = s.dat[0], s.dat[1]
+			s.dat[0], s.dat[1] = at, 0
+			if s.cnt != nil {
+				s.cnt[at>>1] = s.cnt[0]
+			}
+		}
+		s.mid <<= 1
+	}
+}
+
+func checkindex(i int) uint64 {
+	if i < 0 {
+		panic("sparsebits: negative index")
+	}
+	return uint64(i)
+}
@@ -1,6 +1,7 @@
 package sparsebits
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 
@@ -57,3 +58,114 @@ func TestSparseCount(t *testing.T) {
 	b.Set(65, true)
 	require.Equal(t, 1, b.OnesCount())
 }
+
+func TestSparseReclaim(t *testing.T) {
+	b := New(1 << 20)
+
+	rnd := rand.New(rand.NewSource(0))
+	xs := rnd.Perm(b.Len())[:1000]
+
+	for _, x := range xs {
+		b.Set(x, true)
+	}
+	n := len(b.dat)
+	_, wasted := b.MemoryUsage()
+	require.Equal(t, 8*(cap(b.dat)-len(b.dat)), wasted)
+
+	for _, x := range xs {
+		b.Flip(x)
+	}
+	require.Equal(t, 0, b.OnesCount())
+	require.Equal(t, (n-2)/2, b.nfree)
+	require.Equal(t, uint64(0), b.dat[0]|b.dat[1])
+
+	for _, x := range xs {
+		b.Set(x, true)
+	}
+	require.Equal(t, n, len(b.dat))
+	require.Equal(t, 0, b.nfree)
+
+	for _, x := range xs[:500] {
+		b.Set(x, false)
+	}
+	for _, x := range xs[500:] {
+		require.True(t, b.Get(x))
+	}
+
+	_, wasted = b.MemoryUsage()
+	require.True(t, wasted > 0)
+	b.Compact()
+	total, wasted := b.MemoryUsage()
+	require.Equal(t, 0, wasted)
+	require.Equal(t, 8*len(b.dat), total)
+	require.True(t, len(b.dat) < n)
+	require.Equal(t, 500, b.OnesCount())
+	for _, x := range xs[500:] {
+		require.True(t, b.Get(x))
+	}
+
+	b.Set(xs[0], true)
+	require.Equal(t, 501, b.OnesCount())
+}
+
+func TestSparseGrow(t *testing.T) {
+	b := New(100)
+	require.Equal(t, 128, b.Len())
+	require.True(t, !b.Get(1000))
+
+	b.Set(5, true)
+	b.Set(1000, false)
+	require.Equal(t, 128, b.Len())
+
+	b.Set(1000, true)
+	require.Equal(t, 1024, b.Len())
+	require.True(t, b.Get(5))
+	require.True(t, b.Get(1000))
+	require.Equal(t, 2, b.OnesCount())
+
+	b.Flip(1 << 40)
+	require.Equal(t, 1<<41, b.Len())
+	require.Equal(t, []int{5, 1000, 1 << 40}, collect(b))
+
+	b.Grow(1 << 50)
+	require.Equal(t, 1<<50, b.Len())
+	require.Equal(t, 3, b.OnesCount())
+
+	var panicked bool
+	func() {
+		defer func() { panicked = recover() != nil }()
+		b.Set(-1, true)
+	}()
+	require.True(t, panicked)
+	require.True(t, !b.Get(-1))
+}
+
+func TestSparseUint64(t *testing.T) {
+	b := New(0)
+	keys := []uint64{0, 1, 1 << 32, math.MaxInt64, math.MaxUint64 - 1, math.MaxUint64}
+	for _, k := range keys {
+		b.SetUint64(k, true)
+	}
+	require.Equal(t, math.MaxInt, b.Len())
+	require.Equal(t, len(keys), b.OnesCount())
+	for _, k := range keys {
+		require.True(t, b.GetUint64(k))
+		require.True(t, !b.GetUint64(k^2))
+	}
+	require.Equal(t, []int{0, 1, 1 << 32}, collect(b))
+
+	for _, k := range keys {
+		b.FlipUint64(k)
+	}
+	require.Equal(t, 0, b.OnesCount())
+	require.Equal(t, 2*b.nfree+2, len(b.dat))
+}
+
+func collect(b *Set) []int {
+	var xs []int
+	b.Range(func(i int) bool {
+		xs = append(xs, i)
+		return true
+	})
+	return xs
+}
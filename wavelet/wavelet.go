@@ -0,0 +1,221 @@
+// Package wavelet implements the wavelet matrix,
+// a succinct data structure for static sequences of integers
+// that answers rank, select and range queries.
+//
+// A wavelet matrix over a sequence of n symbols from an alphabet of size σ
+// uses about n log(σ) bits plus the overhead of the rank and select indexes.
+// Most queries take O(log(σ)) time independent of the size of the range.
+package wavelet
+
+import (
+	"math/bits"
+
+	"github.com/askeladdk/toolbox/densebits"
+	"github.com/askeladdk/toolbox/internal/rankselect"
+)
+
+// References:
+// The Wavelet Matrix
+// https://users.dcc.uchile.cl/~gnavarro/ps/spire12.4.pdf
+
+// Matrix is an immutable wavelet matrix over a sequence of uint32 symbols.
+type Matrix struct {
+	levels []*rankselect.Vector
+	zeros  []int
+	n      int
+}
+
+// New builds a wavelet matrix over values.
+// The values are copied and may be modified afterwards.
+// The complexity is O(n log(σ)), where σ is the largest value plus one.
+func New(values []uint32) *Matrix {
+	var maxval uint32
+	for _, x := range values {
+		maxval = max(maxval, x)
+	}
+
+	nlevels := max(bits.Len32(maxval), 1)
+	m := &Matrix{
+		levels: make([]*rankselect.Vector, nlevels),
+		zeros:  make([]int, nlevels),
+		n:      len(values),
+	}
+
+	cur := append([]uint32(nil), values...)
+	next := make([]uint32, len(values))
+	for d := 0; d < nlevels; d++ {
+		shift := nlevels - 1 - d
+		bv := densebits.New(len(cur))
+		zeros := 0
+		for i, x := range cur {
+			if x>>shift&1 != 0 {
+				bv.Set(i, true)
+			} else {
+				zeros++
+			}
+		}
+
+		// stable partition of the values by the current bit,
+		// zeros first, to obtain the order of the next level.
+		z, o := 0, zeros
+		for _, x := range cur {
+			if x>>shift&1 != 0 {
+				next[o] = x
+				o++
+			} else {
+				next[z] = x
+				z++
+			}
+		}
+
+		m.levels[d] = rankselect.New(bv, len(cur))
+		m.zeros[d] = zeros
+		cur, next = next, cur
+	}
+	return m
+}
+
+// Len reports the number of symbols in m.
+func (m *Matrix) Len() int {
+	return m.n
+}
+
+// Access returns the i-th symbol.
+// The complexity is O(log(σ)).
+func (m *Matrix) Access(i int) uint32 {
+	if i < 0 || i >= m.n {
+		panic("wavelet: index out of range")
+	}
+	var x uint32
+	for d, lv := range m.levels {
+		x <<= 1
+		if lv.Get(i) {
+			x |= 1
+			i = m.zeros[d] + lv.Rank1(i)
+		} else {
+			i = lv.Rank0(i)
+		}
+	}
+	return x
+}
+
+// Rank reports the number of occurrences of c in the range [0, i).
+// The complexity is O(log(σ)).
+func (m *Matrix) Rank(c uint32, i int) int {
+	return m.Count(c, 0, i)
+}
+
+// Count reports the number of occurrences of c in the range [lo, hi).
+// The complexity is O(log(σ)).
+func (m *Matrix) Count(c uint32, lo, hi int) int {
+	m.checkRange(lo, hi)
+	if bits.Len32(c) > len(m.levels) {
+		return 0
+	}
+	for d := range m.levels {
+		lo, hi = m.descend(d, c, lo, hi)
+	}
+	return hi - lo
+}
+
+// Select returns the position of the k-th occurrence of c,
+// counting from zero.
+// Returns -1 if c occurs at most k times.
+// The complexity is O(log(σ) log(n)).
+func (m *Matrix) Select(c uint32, k int) int {
+	if k < 0 || bits.Len32(c) > len(m.levels) {
+		return -1
+	}
+	lo, hi := 0, m.n
+	for d := range m.levels {
+		lo, hi = m.descend(d, c, lo, hi)
+	}
+	if k >= hi-lo {
+		return -1
+	}
+	i := lo + k
+	for d := len(m.levels) - 1; d >= 0; d-- {
+		if m.bit(d, c) {
+			i = m.levels[d].Select1(i - m.zeros[d])
+		} else {
+			i = m.levels[d].Select0(i)
+		}
+	}
+	return i
+}
+
+// Quantile returns the k-th smallest symbol in the range [lo, hi),
+// counting from zero.
+// Quantile(lo, hi, 0) is the minimum and Quantile(lo, hi, hi-lo-1) is the maximum.
+// Panics if k is not in the range [0, hi-lo).
+// The complexity is O(log(σ)).
+func (m *Matrix) Quantile(lo, hi, k int) uint32 {
+	m.checkRange(lo, hi)
+	if k < 0 || k >= hi-lo {
+		panic("wavelet: quantile out of range")
+	}
+	var x uint32
+	for d, lv := range m.levels {
+		x <<= 1
+		lo0, hi0 := lv.Rank0(lo), lv.Rank0(hi)
+		if z := hi0 - lo0; k < z {
+			lo, hi = lo0, hi0
+		} else {
+			k -= z
+			x |= 1
+			lo, hi = m.zeros[d]+lo-lo0, m.zeros[d]+hi-hi0
+		}
+	}
+	return x
+}
+
+// RangeFreq reports the number of symbols in the range [lo, hi)
+// whose value lies in the range [a, b).
+// The complexity is O(log(σ)).
+func (m *Matrix) RangeFreq(lo, hi int, a, b uint64) int {
+	m.checkRange(lo, hi)
+	if a >= b {
+		return 0
+	}
+	return m.countLess(lo, hi, b) - m.countLess(lo, hi, a)
+}
+
+// countLess reports the number of symbols in [lo, hi) that are less than x.
+func (m *Matrix) countLess(lo, hi int, x uint64) int {
+	if x >= 1<<len(m.levels) {
+		return hi - lo
+	}
+	c := uint32(x)
+	count := 0
+	for d, lv := range m.levels {
+		lo0, hi0 := lv.Rank0(lo), lv.Rank0(hi)
+		if m.bit(d, c) {
+			count += hi0 - lo0
+			lo, hi = m.zeros[d]+lo-lo0, m.zeros[d]+hi-hi0
+		} else {
+			lo, hi = lo0, hi0
+		}
+	}
+	return count
+}
+
+// descend maps the range [lo, hi) at level d to the range
+// of the next level that follows the d-th bit of c.
+func (m *Matrix) descend(d int, c uint32, lo, hi int) (int, int) {
+	lv := m.levels[d]
+	if m.bit(d, c) {
+		return m.zeros[d] + lv.Rank1(lo), m.zeros[d] + lv.Rank1(hi)
+	}
+	return lv.Rank0(lo), lv.Rank0(hi)
+}
+
+// bit reports whether the bit of c that corresponds to level d is set.
+func (m *Matrix) bit(d int, c uint32) bool {
+	return c>>(len(m.levels)-1-d)&1 != 0
+}
+
+func (m *Matrix) checkRange(lo, hi int) {
+	if lo < 0 || hi > m.n || lo > hi {
+		panic("wavelet: range out of bounds")
+	}
+}
@@ -0,0 +1,113 @@
+package wavelet
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/askeladdk/toolbox/internal/require"
+)
+
+func randomValues(rnd *rand.Rand, n int, sigma uint32) []uint32 {
+	xs := make([]uint32, n)
+	for i := range xs {
+		xs[i] = uint32(rnd.Int63n(int64(sigma)))
+	}
+	return xs
+}
+
+func TestAccess(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	for _, sigma := range []uint32{1, 2, 7, 256, math.MaxUint32} {
+		xs := randomValues(rnd, 1000, sigma)
+		m := New(xs)
+		require.Equal(t, len(xs), m.Len())
+		for i, x := range xs {
+			require.Equal(t, x, m.Access(i))
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	m := New(nil)
+	require.Equal(t, 0, m.Len())
+	require.Equal(t, 0, m.Rank(0, 0))
+	require.Equal(t, -1, m.Select(0, 0))
+	require.Equal(t, 0, m.RangeFreq(0, 0, 0, 10))
+}
+
+func TestRankSelect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	xs := randomValues(rnd, 2000, 20)
+	m := New(xs)
+
+	counts := make(map[uint32]int)
+	for i, x := range xs {
+		require.Equal(t, counts[x], m.Rank(x, i))
+		require.Equal(t, i, m.Select(x, counts[x]))
+		counts[x]++
+	}
+
+	for c := uint32(0); c < 40; c++ {
+		require.Equal(t, counts[c], m.Rank(c, len(xs)))
+		require.Equal(t, -1, m.Select(c, counts[c]))
+	}
+
+	for n := 0; n < 1000; n++ {
+		lo := rnd.Intn(len(xs))
+		hi := lo + rnd.Intn(len(xs)-lo+1)
+		c := uint32(rnd.Intn(20))
+		count := 0
+		for _, x := range xs[lo:hi] {
+			if x == c {
+				count++
+			}
+		}
+		require.Equal(t, count, m.Count(c, lo, hi))
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	xs := randomValues(rnd, 1000, 1<<20)
+	m := New(xs)
+
+	for n := 0; n < 500; n++ {
+		lo := rnd.Intn(len(xs))
+		hi := lo + 1 + rnd.Intn(len(xs)-lo)
+		sorted := slices.Clone(xs[lo:hi])
+		slices.Sort(sorted)
+		k := rnd.Intn(hi - lo)
+		require.Equal(t, sorted[k], m.Quantile(lo, hi, k))
+	}
+
+	var panicked bool
+	func() {
+		defer func() { panicked = recover() != nil }()
+		m.Quantile(10, 10, 0)
+	}()
+	require.True(t, panicked)
+}
+
+func TestRangeFreq(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	xs := randomValues(rnd, 1000, 100)
+	m := New(xs)
+
+	for n := 0; n < 500; n++ {
+		lo := rnd.Intn(len(xs))
+		hi := lo + rnd.Intn(len(xs)-lo+1)
+		a := uint64(rnd.Intn(120))
+		b := uint64(rnd.Intn(120))
+		count := 0
+		for _, x := range xs[lo:hi] {
+			if a <= uint64(x) && uint64(x) < b {
+				count++
+			}
+		}
+		require.Equal(t, count, m.RangeFreq(lo, hi, a, b))
+	}
+
+	require.Equal(t, len(xs), m.RangeFreq(0, len(xs), 0, math.MaxUint64))
+}